@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (cfg *APIConfig) blockHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    blockedID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if blockedID == userID {
+        respondWithError(w, http.StatusBadRequest, "You cannot block yourself")
+        return
+    }
+
+    err = cfg.DB.CreateBlock(r.Context(), database.CreateBlockParams{
+        BlockerID: userID,
+        BlockedID: blockedID,
+    })
+    if err != nil {
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to block user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) unblockHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    blockedID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    err = cfg.DB.DeleteBlock(r.Context(), database.DeleteBlockParams{
+        BlockerID: userID,
+        BlockedID: blockedID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to unblock user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) muteHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    mutedID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if mutedID == userID {
+        respondWithError(w, http.StatusBadRequest, "You cannot mute yourself")
+        return
+    }
+
+    err = cfg.DB.CreateMute(r.Context(), database.CreateMuteParams{
+        MuterID: userID,
+        MutedID: mutedID,
+    })
+    if err != nil {
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to mute user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) unmuteHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    mutedID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    err = cfg.DB.DeleteMute(r.Context(), database.DeleteMuteParams{
+        MuterID: userID,
+        MutedID: mutedID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to unmute user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
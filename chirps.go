@@ -2,44 +2,82 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
     "database/sql"
 	"github.com/KrishKoria/Chirpy/internal/database"
 	"github.com/google/uuid"
-    "github.com/KrishKoria/Chirpy/internal/auth"
     "sort"
+    "strings"
+    "unicode/utf8"
+    "github.com/KrishKoria/Chirpy/internal/moderation"
 )
 
 
 func (cfg *APIConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
     type chirpRequest struct {
-        Body   string `json:"body"`
+        Body       string   `json:"body"`
+        Visibility string   `json:"visibility"`
+        Mentions   []string `json:"mentions"`
     }
 
-    tokenString, err := auth.GetBearerToken(r.Header)
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    var req chirpRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    user, err := cfg.Store.GetUserByID(r.Context(), userID)
     if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Authentication required")
+        respondWithInternalError(w, "Failed to retrieve user", err)
         return
     }
-    userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
+
+    limits, err := cfg.limitsFor(r.Context(), user)
     if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+        respondWithInternalError(w, "Failed to retrieve entitlements", err)
         return
     }
 
-    var req chirpRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+    if utf8.RuneCountInString(req.Body) > limits.MaxChirpLength {
+        respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Chirp is too long (max %d characters)", limits.MaxChirpLength))
         return
     }
 
-    if len(req.Body) > 140 {
-        respondWithError(w, http.StatusBadRequest, "Chirp is too long")
+    if req.Visibility == "" {
+        req.Visibility = visibilityPublic
+    }
+    if !validVisibility(req.Visibility) {
+        respondWithError(w, http.StatusBadRequest, "Invalid visibility")
+        return
+    }
+
+    mentions := make([]uuid.UUID, 0, len(req.Mentions))
+    for _, mention := range req.Mentions {
+        mentionID, err := uuid.Parse(mention)
+        if err != nil {
+            respondWithError(w, http.StatusBadRequest, "Invalid mentioned user ID")
+            return
+        }
+        mentions = append(mentions, mentionID)
+    }
+
+    moderated := cfg.Profanity.Check(req.Body)
+    if moderated.Rejected {
+        respondWithError(w, http.StatusBadRequest, "Chirp contains prohibited language")
+        return
+    }
+    spamViolations, ok := cfg.checkSpam(w, r, user, moderated.Body)
+    if !ok {
         return
     }
 
-    cleaned := cleanProfanity(req.Body)
     chirpID := uuid.New()
     createdAt := time.Now()
     updatedAt := createdAt
@@ -48,26 +86,85 @@ func (cfg *APIConfig) chirpsHandler(w http.ResponseWriter, r *http.Request) {
         ID:        chirpID,
         CreatedAt: createdAt,
         UpdatedAt: updatedAt,
-        Body:      cleaned,
-        UserID:    userID,
+        Body:       moderated.Body,
+        UserID:     userID,
+        Visibility: req.Visibility,
     }
 
-    chirp, err := cfg.DB.CreateChirp(r.Context(), params)
+    // The chirp, its review reports, mentions and outbound events are written
+    // together so webhooks never announce a chirp that failed to save.
+    var chirp database.Chirp
+    err = cfg.inChirpTx(r.Context(), func(q chirpTx) error {
+        var err error
+        chirp, err = q.CreateChirp(r.Context(), params)
+        if err != nil {
+            return err
+        }
+
+        if moderated.Flagged {
+            words := make([]string, 0, len(moderated.Matches))
+            for _, match := range moderated.Matches {
+                if match.Action == moderation.ActionFlag {
+                    words = append(words, match.Word)
+                }
+            }
+            _, err = q.CreateReport(r.Context(), database.CreateReportParams{
+                TargetUserID:  chirp.UserID,
+                TargetChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+                Category:      "profanity",
+                Details:       "matched: " + strings.Join(words, ", "),
+            })
+            if err != nil {
+                return err
+            }
+        }
+
+        if len(spamViolations) > 0 {
+            if err := queueSpamReport(r.Context(), q, chirp, spamViolations); err != nil {
+                return err
+            }
+        }
+
+        // A shadow-banned author's chirp is visible to them alone, so it
+        // must not notify the users it mentions or announce itself.
+        if userShadowBanned(user) {
+            return nil
+        }
+
+        if len(mentions) > 0 {
+            err = q.CreateChirpMentions(r.Context(), database.CreateChirpMentionsParams{
+                ChirpID:  chirp.ID,
+                UserIds:  mentions,
+                AuthorID: userID,
+            })
+            if err != nil {
+                return err
+            }
+
+            err = q.EnqueueMentionEvents(r.Context(), database.EnqueueMentionEventsParams{
+                AuthorID: userID,
+                ChirpID:  chirp.ID,
+            })
+            if err != nil {
+                return err
+            }
+        }
+
+        return enqueueEvent(r.Context(), q, eventChirpCreated, userID, mapChirp(chirp))
+    })
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to create chirp")
+        respondWithInternalError(w, "Failed to create chirp", err)
         return
     }
 
-    respondWithJSON(w, http.StatusCreated, ChirpResponse{
-        ID:        chirp.ID,
-        CreatedAt: chirp.CreatedAt,
-        UpdatedAt: chirp.UpdatedAt,
-        Body:      chirp.Body,
-        UserID:    chirp.UserID,
-    })
+    respondWithJSON(w, http.StatusCreated, mapChirp(chirp))
 }
 
 func (cfg *APIConfig) getAllChirpsHandler(w http.ResponseWriter, r *http.Request) {
+    viewerID, ok := cfg.authenticateOptional(w, r)
+    if !ok {
+        return
+    }
 
     var chirps []database.Chirp
     var err error
@@ -86,15 +183,22 @@ func (cfg *APIConfig) getAllChirpsHandler(w http.ResponseWriter, r *http.Request
             return
         }
         
-        chirps, err = cfg.DB.GetChirpsByAuthor(r.Context(), authorID)
+        chirps, err = cfg.Store.GetChirpsByAuthor(r.Context(), database.GetChirpsByAuthorParams{
+            UserID:   authorID,
+            ViewerID: viewerID,
+            Now:      time.Now().UTC(),
+        })
         if err != nil {
-            respondWithError(w, http.StatusInternalServerError, "Failed to retrieve chirps")
+            respondWithInternalError(w, "Failed to retrieve chirps", err)
             return
         }
     } else {
-        chirps, err = cfg.DB.GetAllChirps(r.Context())
+        chirps, err = cfg.Store.GetAllChirps(r.Context(), database.GetAllChirpsParams{
+            ViewerID: viewerID,
+            Now:      time.Now().UTC(),
+        })
         if err != nil {
-            respondWithError(w, http.StatusInternalServerError, "Failed to retrieve chirps")
+            respondWithInternalError(w, "Failed to retrieve chirps", err)
             return
         }
     }
@@ -106,21 +210,31 @@ func (cfg *APIConfig) getAllChirpsHandler(w http.ResponseWriter, r *http.Request
         return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
     })
 
+    filters, err := cfg.activeKeywordFilters(r.Context(), viewerID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve filters", err)
+        return
+    }
+
     var response []ChirpResponse
     for _, chirp := range chirps {
-        response = append(response, ChirpResponse{
-            ID:        chirp.ID,
-            CreatedAt: chirp.CreatedAt,
-            UpdatedAt: chirp.UpdatedAt,
-            Body:      chirp.Body,
-            UserID:    chirp.UserID,
-        })
+        mapped := mapChirp(chirp)
+        mapped.Filtered = matchKeywordFilter(chirp.Body, filters)
+        if mapped.Filtered != nil && mapped.Filtered.Action == keywordActionHide {
+            continue
+        }
+        response = append(response, mapped)
     }
 
     respondWithJSON(w, http.StatusOK, response)
 }
 
 func (cfg *APIConfig) getChirpHandler(w http.ResponseWriter, r *http.Request) { 
+    viewerID, ok := cfg.authenticateOptional(w, r)
+    if !ok {
+        return
+    }
+
     id := r.PathValue("chirpID")
     chirpID, err := uuid.Parse(id)
     if err != nil {
@@ -128,35 +242,35 @@ func (cfg *APIConfig) getChirpHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    chirp, err := cfg.DB.GetChirpByID(r.Context(), chirpID)
+    chirp, err := cfg.Store.GetChirpByID(r.Context(), database.GetChirpByIDParams{
+        ID:       chirpID,
+        ViewerID: viewerID,
+        Now:      time.Now().UTC(),
+    })
     if err != nil {
         if err == sql.ErrNoRows {
             respondWithError(w, http.StatusNotFound, "Chirp not found")
             return
         }
-        respondWithError(w, http.StatusInternalServerError, "Failed to retrieve chirp")
+        respondWithInternalError(w, "Failed to retrieve chirp", err)
         return
     }
-    response := ChirpResponse{
-        ID:        chirp.ID,
-        CreatedAt: chirp.CreatedAt,
-        UpdatedAt: chirp.UpdatedAt,
-        Body:      chirp.Body,
-        UserID:    chirp.UserID,
+    filters, err := cfg.activeKeywordFilters(r.Context(), viewerID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve filters", err)
+        return
     }
+
+    // A chirp fetched directly is never dropped, only annotated, so the
+    // client can still show it behind its filter warning.
+    response := mapChirp(chirp)
+    response.Filtered = matchKeywordFilter(chirp.Body, filters)
     respondWithJSON(w, http.StatusOK, response)
 }
 
 func (cfg *APIConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request) {
-    tokenString, err := auth.GetBearerToken(r.Header)
-    if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Authentication required")
-        return
-    }
-    
-    userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
-    if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
         return
     }
     
@@ -167,13 +281,17 @@ func (cfg *APIConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
         return
     }
     
-    chirp, err := cfg.DB.GetChirpByID(r.Context(), chirpID)
+    chirp, err := cfg.Store.GetChirpByID(r.Context(), database.GetChirpByIDParams{
+        ID:       chirpID,
+        ViewerID: userID,
+        Now:      time.Now().UTC(),
+    })
     if err != nil {
         if err == sql.ErrNoRows {
             respondWithError(w, http.StatusNotFound, "Chirp not found")
             return
         }
-        respondWithError(w, http.StatusInternalServerError, "Failed to retrieve chirp")
+        respondWithInternalError(w, "Failed to retrieve chirp", err)
         return
     }
     
@@ -182,11 +300,51 @@ func (cfg *APIConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
         return
     }
     
-    err = cfg.DB.DeleteChirp(r.Context(), chirpID)
+    err = cfg.inChirpTx(r.Context(), func(q chirpTx) error {
+        err := q.SoftDeleteChirp(r.Context(), database.SoftDeleteChirpParams{
+            Now: time.Now().UTC(),
+            ID:  chirpID,
+        })
+        if err != nil {
+            return err
+        }
+        return enqueueEvent(r.Context(), q, eventChirpDeleted, userID, map[string]uuid.UUID{"chirp_id": chirpID})
+    })
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp")
+        respondWithInternalError(w, "Failed to delete chirp", err)
         return
     }
     
     w.WriteHeader(http.StatusNoContent)
+}
+
+const (
+    visibilityPublic    = "public"
+    visibilityUnlisted  = "unlisted"
+    visibilityFollowers = "followers"
+    visibilityMentioned = "mentioned"
+)
+
+func validVisibility(visibility string) bool {
+    switch visibility {
+    case visibilityPublic, visibilityUnlisted, visibilityFollowers, visibilityMentioned:
+        return true
+    }
+    return false
+}
+
+func mapChirp(chirp database.Chirp) ChirpResponse {
+    response := ChirpResponse{
+        ID:         chirp.ID,
+        CreatedAt:  chirp.CreatedAt,
+        UpdatedAt:  chirp.UpdatedAt,
+        Body:       chirp.Body,
+        UserID:     chirp.UserID,
+        Visibility: chirp.Visibility,
+    }
+    if chirp.DeletedAt.Valid {
+        deletedAt := chirp.DeletedAt.Time
+        response.DeletedAt = &deletedAt
+    }
+    return response
 }
\ No newline at end of file
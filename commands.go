@@ -0,0 +1,54 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/migrations"
+    "github.com/KrishKoria/Chirpy/internal/store"
+)
+
+const usage = `usage:
+  chirpy                            start the server
+  chirpy grant-admin EMAIL          give an existing user the admin role
+  chirpy migrate up|down|status     apply, roll back one or list migrations`
+
+// runCommand handles the command-line subcommands used to operate a
+// deployment, such as bootstrapping the first admin.
+func runCommand(ctx context.Context, db store.Store, m *migrations.Migrator, args []string) error {
+    switch args[0] {
+    case "migrate":
+        if len(args) != 2 {
+            return errors.New(usage)
+        }
+        return runMigrate(ctx, m, args[1])
+    case "grant-admin":
+        if len(args) != 2 {
+            return errors.New(usage)
+        }
+        return grantAdmin(ctx, db, args[1])
+    default:
+        return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+    }
+}
+
+func grantAdmin(ctx context.Context, db store.Store, email string) error {
+    user, err := db.GetUserByEmail(ctx, email)
+    if err != nil {
+        return fmt.Errorf("no user with email %s: %w", email, err)
+    }
+
+    updated, err := db.SetUserRole(ctx, database.SetUserRoleParams{
+        ID:   user.ID,
+        Role: auth.RoleAdmin,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to grant admin: %w", err)
+    }
+
+    fmt.Printf("%s (%s) is now an admin\n", updated.Email, updated.ID)
+    return nil
+}
@@ -1,19 +1,48 @@
 package main
 
 import (
+	"database/sql"
+	"log/slog"
 	"sync/atomic"
 	"time"
 
+	"github.com/KrishKoria/Chirpy/internal/auth"
 	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/KrishKoria/Chirpy/internal/entitlements"
+	"github.com/KrishKoria/Chirpy/internal/health"
+	"github.com/KrishKoria/Chirpy/internal/metrics"
+	"github.com/KrishKoria/Chirpy/internal/migrations"
+	"github.com/KrishKoria/Chirpy/internal/moderation"
+	"github.com/KrishKoria/Chirpy/internal/outbound"
+	"github.com/KrishKoria/Chirpy/internal/replica"
+	"github.com/KrishKoria/Chirpy/internal/spam"
+	"github.com/KrishKoria/Chirpy/internal/store"
 	"github.com/google/uuid"
 )
 
 type APIConfig struct {
-    FileserverHits atomic.Int32
-    DB             *database.Queries
-    Platform       string
-    JWTSecret      string
-    PolkaKey       string
+    FileserverHits    atomic.Int32
+    Store             store.Store
+    Extras            Extras
+    DB                *database.Queries
+    Conn              *sql.DB
+    Replicas          *replica.Router
+    Migrations        *migrations.Migrator
+    Platform          string
+    JWTSecret         string
+    AccessTokenTTL    time.Duration
+    RefreshTokenTTL   time.Duration
+    PolkaWebhooks     *auth.WebhookVerifier
+    ChirpRetention    time.Duration
+    SubscriptionGrace time.Duration
+    Profanity         *moderation.Filter
+    Spam              *spam.Detector
+    Entitlements      *entitlements.Registry
+    Outbound          *outbound.Sender
+    Metrics           *metrics.Metrics
+    Logger            *slog.Logger
+    HealthChecks      []health.Check
+    Workers           []*worker
 }
 
 type User struct {
@@ -22,6 +51,7 @@ type User struct {
     UpdatedAt time.Time `json:"updated_at"`
     Email     string    `json:"email"`
     IsChirpyRed bool    `json:"is_chirpy_red"`
+    IsPrivate   bool    `json:"is_private"`
 }
 
 type ChirpResponse struct {
@@ -30,4 +60,13 @@ type ChirpResponse struct {
     UpdatedAt time.Time `json:"updated_at"`
     Body      string    `json:"body"`
     UserID    uuid.UUID `json:"user_id"`
+    Visibility string   `json:"visibility"`
+    DeletedAt *time.Time `json:"deleted_at,omitempty"`
+    Filtered  *FilterMatch `json:"filtered,omitempty"`
+}
+
+type FilterMatch struct {
+    FilterID uuid.UUID `json:"filter_id"`
+    Phrase   string    `json:"phrase"`
+    Action   string    `json:"action"`
 }
\ No newline at end of file
@@ -0,0 +1,114 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "net/http"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/entitlements"
+)
+
+const entitlementsSetting = "entitlements"
+
+// reloadEntitlements loads the stored plan limits, falling back to the
+// defaults when none have been configured.
+func (cfg *APIConfig) reloadEntitlements(ctx context.Context) error {
+    plans := entitlements.DefaultPlans()
+
+    value, err := cfg.DB.GetSetting(ctx, entitlementsSetting)
+    if err != nil && err != sql.ErrNoRows {
+        return err
+    }
+    if err == nil {
+        if err := json.Unmarshal(value, &plans); err != nil {
+            return err
+        }
+        if err := plans.Validate(); err != nil {
+            return err
+        }
+    }
+
+    cfg.Entitlements.Load(plans)
+    return nil
+}
+
+// limitsFor returns the limits of the plan the user is currently paying for.
+func (cfg *APIConfig) limitsFor(ctx context.Context, user database.User) (entitlements.Limits, error) {
+    if !user.IsChirpyRed {
+        return cfg.Entitlements.For(entitlements.PlanFree), nil
+    }
+
+    subscription, err := cfg.Extras.GetSubscriptionByUser(ctx, user.ID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return cfg.Entitlements.For(entitlements.PlanChirpyRed), nil
+        }
+        return entitlements.Limits{}, err
+    }
+
+    return cfg.Entitlements.For(subscription.Plan), nil
+}
+
+func (cfg *APIConfig) getMyEntitlementsHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    user, err := cfg.Store.GetUserByID(r.Context(), userID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve user", err)
+        return
+    }
+
+    limits, err := cfg.limitsFor(r.Context(), user)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve entitlements", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, limits)
+}
+
+func (cfg *APIConfig) getEntitlementsHandler(w http.ResponseWriter, r *http.Request) {
+    respondWithJSON(w, http.StatusOK, cfg.Entitlements.Plans())
+}
+
+func (cfg *APIConfig) updateEntitlementsHandler(w http.ResponseWriter, r *http.Request) {
+    // Plans left out of the request keep their current limits.
+    plans := cfg.Entitlements.Plans()
+
+    var updates entitlements.Plans
+    if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+    for name, limits := range updates {
+        plans[name] = limits
+    }
+
+    if err := plans.Validate(); err != nil {
+        respondWithError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    value, err := json.Marshal(plans)
+    if err != nil {
+        respondWithInternalError(w, "Failed to encode plans", err)
+        return
+    }
+
+    err = cfg.DB.UpsertSetting(r.Context(), database.UpsertSettingParams{
+        Key:   entitlementsSetting,
+        Value: value,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to save plans", err)
+        return
+    }
+
+    cfg.Entitlements.Load(plans)
+    respondWithJSON(w, http.StatusOK, plans)
+}
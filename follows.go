@@ -0,0 +1,203 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (cfg *APIConfig) followHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    followeeID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if followeeID == userID {
+        respondWithError(w, http.StatusBadRequest, "You cannot follow yourself")
+        return
+    }
+
+    blocked, err := cfg.DB.IsBlockedBetween(r.Context(), database.IsBlockedBetweenParams{
+        UserA: userID,
+        UserB: followeeID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to follow user", err)
+        return
+    }
+
+    if blocked {
+        respondWithError(w, http.StatusForbidden, "You cannot follow this user")
+        return
+    }
+
+    followee, err := cfg.Store.GetUserByID(r.Context(), followeeID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve user", err)
+        return
+    }
+
+    if followee.IsPrivate {
+        err = cfg.DB.CreateFollowRequest(r.Context(), database.CreateFollowRequestParams{
+            RequesterID: userID,
+            TargetID:    followeeID,
+        })
+        if err != nil {
+            respondWithInternalError(w, "Failed to request follow", err)
+            return
+        }
+
+        respondWithJSON(w, http.StatusAccepted, map[string]string{"status": "pending"})
+        return
+    }
+
+    err = cfg.DB.CreateFollow(r.Context(), database.CreateFollowParams{
+        FollowerID: userID,
+        FolloweeID: followeeID,
+    })
+    if err != nil {
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to follow user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) unfollowHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    followeeID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    err = cfg.DB.DeleteFollow(r.Context(), database.DeleteFollowParams{
+        FollowerID: userID,
+        FolloweeID: followeeID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to unfollow user", err)
+        return
+    }
+
+    _, err = cfg.DB.DeleteFollowRequest(r.Context(), database.DeleteFollowRequestParams{
+        RequesterID: userID,
+        TargetID:    followeeID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to cancel follow request", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) getFollowRequestsHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    requests, err := cfg.DB.GetFollowRequests(r.Context(), userID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve follow requests", err)
+        return
+    }
+
+    type followRequestResponse struct {
+        UserID      uuid.UUID `json:"user_id"`
+        Email       string    `json:"email"`
+        RequestedAt time.Time `json:"requested_at"`
+    }
+
+    response := []followRequestResponse{}
+    for _, request := range requests {
+        response = append(response, followRequestResponse{
+            UserID:      request.RequesterID,
+            Email:       request.Email,
+            RequestedAt: request.CreatedAt,
+        })
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) approveFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    requesterID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    approved, err := cfg.DB.ApproveFollowRequest(r.Context(), database.ApproveFollowRequestParams{
+        RequesterID: requesterID,
+        TargetID:    userID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to approve follow request", err)
+        return
+    }
+
+    if approved == 0 {
+        respondWithError(w, http.StatusNotFound, "Follow request not found")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) rejectFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    requesterID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    rejected, err := cfg.DB.DeleteFollowRequest(r.Context(), database.DeleteFollowRequestParams{
+        RequesterID: requesterID,
+        TargetID:    userID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to reject follow request", err)
+        return
+    }
+
+    if rejected == 0 {
+        respondWithError(w, http.StatusNotFound, "Follow request not found")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/health"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// LivenessHandler answers as long as the process can serve HTTP. It checks
+// nothing else so an orchestrator doesn't restart the server over a
+// database outage.
+func LivenessHandler(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    w.WriteHeader(http.StatusOK)
+    w.Write([]byte("OK"))
+}
+
+type workerStatus struct {
+    Name      string    `json:"name"`
+    Status    string    `json:"status"`
+    StartedAt time.Time `json:"started_at"`
+}
+
+// readinessHandler reports whether the server should receive traffic: the
+// database answers, its schema matches this build and every background
+// worker is running. Optional dependencies are listed but never make the
+// server unready.
+func (cfg *APIConfig) readinessHandler(w http.ResponseWriter, r *http.Request) {
+    report := health.Run(r.Context(), readinessTimeout, cfg.HealthChecks)
+
+    workers := make([]workerStatus, 0, len(cfg.Workers))
+    for _, wk := range cfg.Workers {
+        status := "running"
+        if !wk.running() {
+            status = "stopped"
+            report.Status = health.StatusFailing
+        }
+        workers = append(workers, workerStatus{Name: wk.name, Status: status, StartedAt: wk.startedAt})
+    }
+
+    code := http.StatusOK
+    if report.Status != health.StatusOK {
+        code = http.StatusServiceUnavailable
+    }
+    respondWithJSON(w, code, struct {
+        health.Report
+        Workers []workerStatus `json:"workers"`
+    }{report, workers})
+}
+
+type schemaStatus struct {
+    Current  int64 `json:"current"`
+    Expected int64 `json:"expected"`
+}
+
+// checkSchemaVersion compares the newest applied migration with the newest
+// one embedded in the binary.
+func (cfg *APIConfig) checkSchemaVersion(ctx context.Context) (any, error) {
+    current, err := cfg.Migrations.Check(ctx)
+    return schemaStatus{Current: current, Expected: cfg.Migrations.Latest()}, err
+}
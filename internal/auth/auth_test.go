@@ -83,4 +83,19 @@ func TestHashAndCheckPassword(t *testing.T) {
     // Verify incorrect password
     err = CheckPasswordHash("wrong-password", hash)
     assert.Error(t, err)
-}
\ No newline at end of file
+}
+
+func TestRoleAllows(t *testing.T) {
+    // Higher roles inherit the access of lower ones
+    assert.True(t, RoleAllows(RoleAdmin, RoleModerator))
+    assert.True(t, RoleAllows(RoleModerator, RoleModerator))
+    assert.True(t, RoleAllows(RoleModerator, RoleUser))
+
+    // Lower roles cannot reach higher ones
+    assert.False(t, RoleAllows(RoleUser, RoleModerator))
+    assert.False(t, RoleAllows(RoleModerator, RoleAdmin))
+
+    // Unknown roles never grant access
+    assert.False(t, RoleAllows("superuser", RoleUser))
+    assert.False(t, RoleAllows(RoleAdmin, "superuser"))
+}
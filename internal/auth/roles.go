@@ -0,0 +1,32 @@
+package auth
+
+const (
+    RoleUser      = "user"
+    RoleModerator = "moderator"
+    RoleAdmin     = "admin"
+)
+
+var roleRanks = map[string]int{
+    RoleUser:      0,
+    RoleModerator: 1,
+    RoleAdmin:     2,
+}
+
+func ValidRole(role string) bool {
+    _, ok := roleRanks[role]
+    return ok
+}
+
+// RoleAllows reports whether a user holding role has at least the access of
+// required. Roles are ordered user < moderator < admin.
+func RoleAllows(role, required string) bool {
+    have, ok := roleRanks[role]
+    if !ok {
+        return false
+    }
+    need, ok := roleRanks[required]
+    if !ok {
+        return false
+    }
+    return have >= need
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func signedHeaders(signature string, timestamp time.Time) http.Header {
+    headers := http.Header{}
+    headers.Set("X-Signature", signature)
+    headers.Set("X-Timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+    return headers
+}
+
+func TestWebhookVerifier(t *testing.T) {
+    body := []byte(`{"event":"user.upgraded"}`)
+    now := time.Now()
+
+    v := NewWebhookVerifier("X-Signature", "X-Timestamp", 5*time.Minute, "old-key", "new-key")
+
+    // Signed with either active key
+    assert.NoError(t, v.Verify(signedHeaders(SignWebhook("old-key", now, body), now), body))
+    assert.NoError(t, v.Verify(signedHeaders(SignWebhook("new-key", now.Add(time.Second), body), now.Add(time.Second)), body))
+
+    // One valid signature among several is enough
+    ts := now.Add(2 * time.Second)
+    mixed := SignWebhook("retired-key", ts, body) + ", " + SignWebhook("new-key", ts, body)
+    assert.NoError(t, v.Verify(signedHeaders(mixed, ts), body))
+}
+
+func TestWebhookVerifierRejects(t *testing.T) {
+    body := []byte(`{"event":"user.upgraded"}`)
+    now := time.Now()
+
+    v := NewWebhookVerifier("X-Signature", "X-Timestamp", 5*time.Minute, "key")
+
+    // Missing headers
+    assert.ErrorIs(t, v.Verify(http.Header{}, body), ErrMissingSignature)
+
+    // Wrong key
+    err := v.Verify(signedHeaders(SignWebhook("other", now, body), now), body)
+    assert.ErrorIs(t, err, ErrInvalidSignature)
+
+    // Tampered body
+    err = v.Verify(signedHeaders(SignWebhook("key", now, body), now), []byte(`{}`))
+    assert.ErrorIs(t, err, ErrInvalidSignature)
+
+    // Stale timestamp
+    old := now.Add(-10 * time.Minute)
+    err = v.Verify(signedHeaders(SignWebhook("key", old, body), old), body)
+    assert.ErrorIs(t, err, ErrStaleTimestamp)
+
+    // A delivery that was verified but not handled may be retried
+    headers := signedHeaders(SignWebhook("key", now, body), now)
+    assert.NoError(t, v.Verify(headers, body))
+    assert.NoError(t, v.Verify(headers, body))
+
+    // Replayed delivery
+    v.Delivered(headers, body)
+    assert.ErrorIs(t, v.Verify(headers, body), ErrReplayedWebhook)
+
+    // Re-encoding or reordering the signatures is still a replay
+    signature := SignWebhook("key", now, body)
+    upper := signatureVersion + "=" + strings.ToUpper(strings.TrimPrefix(signature, signatureVersion+"="))
+    assert.ErrorIs(t, v.Verify(signedHeaders(upper, now), body), ErrReplayedWebhook)
+    reordered := SignWebhook("other", now, body) + "," + signature
+    assert.ErrorIs(t, v.Verify(signedHeaders(reordered, now), body), ErrReplayedWebhook)
+}
@@ -0,0 +1,402 @@
+// Package config loads Chirpy's settings from, in increasing order of
+// precedence, built-in defaults, an optional YAML or TOML file, an optional
+// .env file and the process environment.
+//
+// File keys are the environment variable names in lower case, so
+// JWT_SECRET is jwt_secret in a file.
+package config
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/BurntSushi/toml"
+    "github.com/joho/godotenv"
+    "gopkg.in/yaml.v3"
+)
+
+const (
+    // MinSecretLength is the shortest JWT secret accepted. HS256 keys should
+    // be at least as long as the 256-bit hash.
+    MinSecretLength = 32
+    // MinWebhookKeyLength is the shortest Polka signing key accepted.
+    MinWebhookKeyLength = 16
+)
+
+// Config is the validated application configuration.
+type Config struct {
+    Port     int
+    Platform string
+
+    DatabaseURL    string
+    DBMaxOpenConns int
+    DBMaxIdleConns int
+    DBConnMaxLife  time.Duration
+    DBConnMaxIdle  time.Duration
+
+    // ReplicaURLs are Postgres read replicas that serve the chirp listing
+    // queries. ReplicaStickiness is how long a user who wrote keeps reading
+    // from the primary.
+    ReplicaURLs       []string
+    ReplicaStickiness time.Duration
+
+    // AutoMigrate applies pending migrations when the server starts.
+    AutoMigrate bool
+
+    JWTSecret       string
+    PolkaKeys       []string
+    AccessTokenTTL  time.Duration
+    RefreshTokenTTL time.Duration
+
+    ReadTimeout       time.Duration
+    ReadHeaderTimeout time.Duration
+    WriteTimeout      time.Duration
+    IdleTimeout       time.Duration
+    ShutdownTimeout   time.Duration
+    MaxHeaderBytes    int
+    MaxBodyBytes      int64
+}
+
+// Default returns the configuration used for any setting left unset.
+func Default() Config {
+    return Config{
+        Port:     8080,
+        Platform: "prod",
+
+        DBMaxOpenConns: 25,
+        DBMaxIdleConns: 25,
+        DBConnMaxLife:  30 * time.Minute,
+        DBConnMaxIdle:  5 * time.Minute,
+
+        ReplicaStickiness: 10 * time.Second,
+
+        AccessTokenTTL:  time.Hour,
+        RefreshTokenTTL: 60 * 24 * time.Hour,
+
+        ReadTimeout:       15 * time.Second,
+        ReadHeaderTimeout: 5 * time.Second,
+        WriteTimeout:      30 * time.Second,
+        IdleTimeout:       2 * time.Minute,
+        ShutdownTimeout:   20 * time.Second,
+        MaxHeaderBytes:    64 << 10,
+        MaxBodyBytes:      1 << 20,
+    }
+}
+
+// Addr is the listen address for the HTTP server.
+func (c Config) Addr() string {
+    return ":" + strconv.Itoa(c.Port)
+}
+
+const (
+    StoragePostgres = "postgres"
+    StorageMemory   = "memory"
+    StorageSQLite   = "sqlite"
+)
+
+// Storage names the backend DB_URL selects: StoragePostgres for postgres://
+// and postgresql:// URLs or a key=value connection string, StorageSQLite
+// for sqlite: and sqlite3: paths, and StorageMemory for "memory:". It is
+// empty for any other scheme.
+func (c Config) Storage() string {
+    scheme, _, found := strings.Cut(c.DatabaseURL, ":")
+    switch {
+    case !found || strings.Contains(scheme, "="):
+        return StoragePostgres
+    case scheme == "postgres" || scheme == "postgresql":
+        return StoragePostgres
+    case scheme == "sqlite" || scheme == "sqlite3":
+        return StorageSQLite
+    case scheme == "memory":
+        return StorageMemory
+    }
+    return ""
+}
+
+// SQLitePath is the database file named by an SQLite DB_URL. Both
+// sqlite:chirpy.db and sqlite:///var/lib/chirpy/chirpy.db are accepted.
+func (c Config) SQLitePath() string {
+    _, path, _ := strings.Cut(c.DatabaseURL, ":")
+    return strings.TrimPrefix(path, "//")
+}
+
+// Load reads the configuration. The file named by CHIRPY_CONFIG is read if
+// set, and .env is read if it exists. Every problem found is reported in the
+// returned error, not just the first.
+func Load() (Config, error) {
+    if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return Config{}, fmt.Errorf("read .env: %w", err)
+    }
+
+    values := map[string]string{}
+    if path := os.Getenv("CHIRPY_CONFIG"); path != "" {
+        fileValues, err := readFile(path)
+        if err != nil {
+            return Config{}, err
+        }
+        values = fileValues
+    }
+
+    for _, key := range keys {
+        if value := os.Getenv(key); value != "" {
+            values[key] = value
+        }
+    }
+
+    return Parse(values)
+}
+
+// keys lists every setting, by environment variable name.
+var keys = []string{
+    "PORT", "PLATFORM",
+    "DB_URL", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME",
+    "DB_REPLICA_URLS", "DB_REPLICA_STICKINESS",
+    "AUTO_MIGRATE",
+    "JWT_SECRET", "POLKA_KEY", "ACCESS_TOKEN_TTL", "REFRESH_TOKEN_TTL",
+    "HTTP_READ_TIMEOUT", "HTTP_READ_HEADER_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT",
+    "SHUTDOWN_TIMEOUT", "HTTP_MAX_HEADER_BYTES", "HTTP_MAX_BODY_BYTES",
+}
+
+// Parse builds a Config from values keyed by environment variable name,
+// applying defaults for missing keys, and validates the result.
+func Parse(values map[string]string) (Config, error) {
+    cfg := Default()
+    p := parser{values: values}
+
+    p.int("PORT", &cfg.Port)
+    p.string("PLATFORM", &cfg.Platform)
+
+    p.string("DB_URL", &cfg.DatabaseURL)
+    p.int("DB_MAX_OPEN_CONNS", &cfg.DBMaxOpenConns)
+    p.int("DB_MAX_IDLE_CONNS", &cfg.DBMaxIdleConns)
+    p.duration("DB_CONN_MAX_LIFETIME", &cfg.DBConnMaxLife)
+    p.duration("DB_CONN_MAX_IDLE_TIME", &cfg.DBConnMaxIdle)
+    p.list("DB_REPLICA_URLS", &cfg.ReplicaURLs)
+    p.duration("DB_REPLICA_STICKINESS", &cfg.ReplicaStickiness)
+    p.bool("AUTO_MIGRATE", &cfg.AutoMigrate)
+
+    p.string("JWT_SECRET", &cfg.JWTSecret)
+    p.list("POLKA_KEY", &cfg.PolkaKeys)
+    p.duration("ACCESS_TOKEN_TTL", &cfg.AccessTokenTTL)
+    p.duration("REFRESH_TOKEN_TTL", &cfg.RefreshTokenTTL)
+
+    p.duration("HTTP_READ_TIMEOUT", &cfg.ReadTimeout)
+    p.duration("HTTP_READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout)
+    p.duration("HTTP_WRITE_TIMEOUT", &cfg.WriteTimeout)
+    p.duration("HTTP_IDLE_TIMEOUT", &cfg.IdleTimeout)
+    p.duration("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout)
+    p.int("HTTP_MAX_HEADER_BYTES", &cfg.MaxHeaderBytes)
+    p.int64("HTTP_MAX_BODY_BYTES", &cfg.MaxBodyBytes)
+
+    problems := append(p.problems, cfg.validate()...)
+    if len(problems) > 0 {
+        return cfg, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+    }
+    return cfg, nil
+}
+
+func (c Config) validate() []string {
+    var problems []string
+
+    if c.DatabaseURL == "" {
+        problems = append(problems, "DB_URL is required")
+    } else if c.Storage() == "" {
+        problems = append(problems, `DB_URL must be a postgres:// URL, a sqlite: path or "memory:"`)
+    } else if c.Storage() == StorageSQLite && c.SQLitePath() == "" {
+        problems = append(problems, "DB_URL must name a file after sqlite:")
+    }
+    if len(c.ReplicaURLs) > 0 && c.Storage() != StoragePostgres {
+        problems = append(problems, "DB_REPLICA_URLS needs a Postgres DB_URL")
+    }
+    for _, url := range c.ReplicaURLs {
+        if (Config{DatabaseURL: url}).Storage() != StoragePostgres {
+            problems = append(problems, "each DB_REPLICA_URLS entry must be a postgres:// URL")
+            break
+        }
+    }
+    if c.ReplicaStickiness < 0 {
+        problems = append(problems, "DB_REPLICA_STICKINESS must not be negative")
+    }
+    if c.Port < 1 || c.Port > 65535 {
+        problems = append(problems, "PORT must be between 1 and 65535")
+    }
+    if c.Platform != "dev" && c.Platform != "prod" {
+        problems = append(problems, `PLATFORM must be "dev" or "prod"`)
+    }
+
+    switch {
+    case c.JWTSecret == "":
+        problems = append(problems, "JWT_SECRET is required")
+    case len(c.JWTSecret) < MinSecretLength:
+        problems = append(problems, fmt.Sprintf("JWT_SECRET must be at least %d characters", MinSecretLength))
+    case weakSecret(c.JWTSecret):
+        problems = append(problems, "JWT_SECRET is too repetitive; generate it randomly, e.g. openssl rand -base64 48")
+    }
+
+    if len(c.PolkaKeys) == 0 {
+        problems = append(problems, "POLKA_KEY is required")
+    }
+    for _, key := range c.PolkaKeys {
+        if len(key) < MinWebhookKeyLength {
+            problems = append(problems, fmt.Sprintf("each POLKA_KEY must be at least %d characters", MinWebhookKeyLength))
+            break
+        }
+    }
+
+    if c.RefreshTokenTTL <= c.AccessTokenTTL {
+        problems = append(problems, "REFRESH_TOKEN_TTL must be longer than ACCESS_TOKEN_TTL")
+    }
+    if c.DBMaxOpenConns < 1 {
+        problems = append(problems, "DB_MAX_OPEN_CONNS must be at least 1")
+    }
+    if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+        problems = append(problems, "DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+    }
+    return problems
+}
+
+// weakSecret catches secrets made of very few distinct characters, such as
+// a long run of one letter, which pass the length check but are guessable.
+func weakSecret(secret string) bool {
+    distinct := map[rune]bool{}
+    for _, c := range secret {
+        distinct[c] = true
+    }
+    return len(distinct) < 8
+}
+
+// readFile reads a flat YAML or TOML file, chosen by extension, into values
+// keyed by environment variable name.
+func readFile(path string) (map[string]string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("read config file: %w", err)
+    }
+
+    raw := map[string]any{}
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &raw)
+    case ".toml":
+        err = toml.Unmarshal(data, &raw)
+    default:
+        return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("parse config file %s: %w", path, err)
+    }
+
+    known := map[string]bool{}
+    for _, key := range keys {
+        known[key] = true
+    }
+
+    values := map[string]string{}
+    var unknown []string
+    for key, value := range raw {
+        name := strings.ToUpper(key)
+        if !known[name] {
+            unknown = append(unknown, key)
+            continue
+        }
+        values[name] = fileValue(value)
+    }
+    if len(unknown) > 0 {
+        return nil, fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(unknown, ", "))
+    }
+    return values, nil
+}
+
+// fileValue turns a decoded value into the string form the environment
+// would use. Lists become comma-separated, as POLKA_KEY expects.
+func fileValue(value any) string {
+    if list, ok := value.([]any); ok {
+        parts := make([]string, 0, len(list))
+        for _, item := range list {
+            parts = append(parts, fmt.Sprint(item))
+        }
+        return strings.Join(parts, ",")
+    }
+    return fmt.Sprint(value)
+}
+
+// parser converts raw values, collecting a problem for each one that does
+// not parse instead of stopping at the first.
+type parser struct {
+    values   map[string]string
+    problems []string
+}
+
+func (p *parser) string(key string, dst *string) {
+    if value, ok := p.values[key]; ok {
+        *dst = value
+    }
+}
+
+// list reads a comma-separated value, skipping empty entries.
+func (p *parser) list(key string, dst *[]string) {
+    if value, ok := p.values[key]; ok {
+        for _, item := range strings.Split(value, ",") {
+            if item = strings.TrimSpace(item); item != "" {
+                *dst = append(*dst, item)
+            }
+        }
+    }
+}
+
+func (p *parser) int(key string, dst *int) {
+    value, ok := p.values[key]
+    if !ok {
+        return
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        p.problems = append(p.problems, key+" must be an integer")
+        return
+    }
+    *dst = n
+}
+
+func (p *parser) int64(key string, dst *int64) {
+    value, ok := p.values[key]
+    if !ok {
+        return
+    }
+    n, err := strconv.ParseInt(value, 10, 64)
+    if err != nil || n <= 0 {
+        p.problems = append(p.problems, key+" must be a positive integer")
+        return
+    }
+    *dst = n
+}
+
+func (p *parser) bool(key string, dst *bool) {
+    value, ok := p.values[key]
+    if !ok {
+        return
+    }
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        p.problems = append(p.problems, key+" must be true or false")
+        return
+    }
+    *dst = b
+}
+
+func (p *parser) duration(key string, dst *time.Duration) {
+    value, ok := p.values[key]
+    if !ok {
+        return
+    }
+    d, err := time.ParseDuration(value)
+    if err != nil || d <= 0 {
+        p.problems = append(p.problems, key+` must be a positive duration such as "30s" or "1h"`)
+        return
+    }
+    *dst = d
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+const testSecret = "kq7P2xVb9LmN4sRt8WzYc3Hd6Fg1Jh5A"
+
+func validValues() map[string]string {
+    return map[string]string{
+        "DB_URL":     "postgres://localhost/chirpy",
+        "JWT_SECRET": testSecret,
+        "POLKA_KEY":  "f271c81ff7084ee5b99a5091b42d486e",
+    }
+}
+
+func TestParseAppliesDefaults(t *testing.T) {
+    cfg, err := Parse(validValues())
+    assert.NoError(t, err)
+    assert.Equal(t, ":8080", cfg.Addr())
+    assert.Equal(t, time.Hour, cfg.AccessTokenTTL)
+    assert.Equal(t, 60*24*time.Hour, cfg.RefreshTokenTTL)
+    assert.Equal(t, []string{"f271c81ff7084ee5b99a5091b42d486e"}, cfg.PolkaKeys)
+}
+
+func TestParseReportsEveryProblem(t *testing.T) {
+    _, err := Parse(map[string]string{
+        "PORT":             "http",
+        "JWT_SECRET":       "short",
+        "ACCESS_TOKEN_TTL": "forever",
+    })
+    assert.Error(t, err)
+    for _, problem := range []string{"PORT", "ACCESS_TOKEN_TTL", "DB_URL", "JWT_SECRET must be at least", "POLKA_KEY is required"} {
+        assert.Contains(t, err.Error(), problem)
+    }
+}
+
+func TestParseRejectsWeakSecret(t *testing.T) {
+    values := validValues()
+    values["JWT_SECRET"] = "abababababababababababababababababab"
+
+    _, err := Parse(values)
+    assert.ErrorContains(t, err, "repetitive")
+}
+
+func TestLoadFromFiles(t *testing.T) {
+    dir := t.TempDir()
+
+    yamlPath := filepath.Join(dir, "chirpy.yaml")
+    os.WriteFile(yamlPath, []byte(`
+db_url: postgres://localhost/chirpy
+jwt_secret: `+testSecret+`
+polka_key:
+  - f271c81ff7084ee5b99a5091b42d486e
+  - 0c1b6ad7a6e44e9f8d3c3e9a4b8f1d2e
+port: 9000
+access_token_ttl: 15m
+`), 0o600)
+
+    tomlPath := filepath.Join(dir, "chirpy.toml")
+    os.WriteFile(tomlPath, []byte(`
+db_url = "postgres://localhost/chirpy"
+jwt_secret = "`+testSecret+`"
+polka_key = "f271c81ff7084ee5b99a5091b42d486e"
+db_max_open_conns = 10
+db_max_idle_conns = 5
+`), 0o600)
+
+    t.Setenv("CHIRPY_CONFIG", yamlPath)
+    cfg, err := Load()
+    assert.NoError(t, err)
+    assert.Equal(t, 9000, cfg.Port)
+    assert.Equal(t, 15*time.Minute, cfg.AccessTokenTTL)
+    assert.Len(t, cfg.PolkaKeys, 2)
+
+    // The environment wins over the file.
+    t.Setenv("PORT", "9100")
+    cfg, err = Load()
+    assert.NoError(t, err)
+    assert.Equal(t, 9100, cfg.Port)
+
+    t.Setenv("CHIRPY_CONFIG", tomlPath)
+    cfg, err = Load()
+    assert.NoError(t, err)
+    assert.Equal(t, 10, cfg.DBMaxOpenConns)
+    assert.Equal(t, 5, cfg.DBMaxIdleConns)
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "chirpy.yaml")
+    os.WriteFile(path, []byte("jwt_secert: oops\n"), 0o600)
+
+    t.Setenv("CHIRPY_CONFIG", path)
+    _, err := Load()
+    assert.ErrorContains(t, err, "jwt_secert")
+}
+
+func TestStorage(t *testing.T) {
+    for url, want := range map[string]string{
+        "postgres://localhost/chirpy":   StoragePostgres,
+        "postgresql://localhost/chirpy": StoragePostgres,
+        "host=localhost dbname=chirpy":  StoragePostgres,
+        "memory:":                       StorageMemory,
+        "sqlite:chirpy.db":              StorageSQLite,
+        "sqlite3:///var/lib/chirpy.db":  StorageSQLite,
+        "mysql://localhost/chirpy":      "",
+    } {
+        assert.Equal(t, want, Config{DatabaseURL: url}.Storage(), url)
+    }
+
+    values := validValues()
+    values["DB_URL"] = "mysql://localhost/chirpy"
+    _, err := Parse(values)
+    assert.ErrorContains(t, err, "DB_URL must be")
+
+    values["DB_URL"] = "sqlite:"
+    _, err = Parse(values)
+    assert.ErrorContains(t, err, "DB_URL must name a file")
+}
+
+func TestSQLitePath(t *testing.T) {
+    for url, want := range map[string]string{
+        "sqlite:chirpy.db":             "chirpy.db",
+        "sqlite://data/chirpy.db":      "data/chirpy.db",
+        "sqlite:///var/lib/chirpy.db":  "/var/lib/chirpy.db",
+        "sqlite3:///var/lib/chirpy.db": "/var/lib/chirpy.db",
+    } {
+        assert.Equal(t, want, Config{DatabaseURL: url}.SQLitePath(), url)
+    }
+}
+
+func TestReplicaURLs(t *testing.T) {
+    values := validValues()
+    values["DB_REPLICA_URLS"] = "postgres://replica-1/chirpy, postgres://replica-2/chirpy,"
+    cfg, err := Parse(values)
+    assert.NoError(t, err)
+    assert.Equal(t, []string{"postgres://replica-1/chirpy", "postgres://replica-2/chirpy"}, cfg.ReplicaURLs)
+    assert.Equal(t, 10*time.Second, cfg.ReplicaStickiness)
+
+    values["DB_REPLICA_URLS"] = "sqlite:replica.db"
+    _, err = Parse(values)
+    assert.ErrorContains(t, err, "each DB_REPLICA_URLS entry must be a postgres:// URL")
+
+    values["DB_URL"] = "memory:"
+    values["DB_REPLICA_URLS"] = "postgres://replica-1/chirpy"
+    _, err = Parse(values)
+    assert.ErrorContains(t, err, "DB_REPLICA_URLS needs a Postgres DB_URL")
+}
@@ -10,20 +10,57 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpsByAuthorSince = `-- name: CountChirpsByAuthorSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = $1 AND created_at >= $2::timestamp
+`
+
+type CountChirpsByAuthorSinceParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountChirpsByAuthorSince(ctx context.Context, arg CountChirpsByAuthorSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByAuthorSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countDuplicateChirpsSince = `-- name: CountDuplicateChirpsSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = $1 AND body = $2 AND created_at >= $3::timestamp
+`
+
+type CountDuplicateChirpsSinceParams struct {
+	UserID uuid.UUID
+	Body   string
+	Since  time.Time
+}
+
+func (q *Queries) CountDuplicateChirpsSince(ctx context.Context, arg CountDuplicateChirpsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDuplicateChirpsSince, arg.UserID, arg.Body, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, visibility)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	Visibility string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -33,6 +70,7 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.Visibility,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -41,28 +79,93 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1
+const createChirpMentions = `-- name: CreateChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT $1::uuid, users.id
+FROM users
+WHERE users.id = ANY($2::uuid[])
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $3::uuid AND blocks.blocked_id = users.id)
+       OR (blocks.blocker_id = users.id AND blocks.blocked_id = $3::uuid)
+  )
+ON CONFLICT DO NOTHING
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
+type CreateChirpMentionsParams struct {
+	ChirpID  uuid.UUID
+	UserIds  []uuid.UUID
+	AuthorID uuid.UUID
+}
+
+func (q *Queries) CreateChirpMentions(ctx context.Context, arg CreateChirpMentionsParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpMentions, arg.ChirpID, pq.Array(arg.UserIds), arg.AuthorID)
 	return err
 }
 
 const getAllChirps = `-- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
+WHERE deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $1::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1::uuid)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $1::uuid AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    user_id = $1::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility = 'public'
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $1::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $1::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $1::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > $2::timestamp)
+      )
+    )
+  )
 ORDER BY created_at ASC
 `
 
-func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirps)
+type GetAllChirpsParams struct {
+	ViewerID uuid.UUID
+	Now      time.Time
+}
+
+func (q *Queries) GetAllChirps(ctx context.Context, arg GetAllChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirps, arg.ViewerID, arg.Now)
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +179,9 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -91,13 +197,57 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirpByID = `-- name: GetChirpByID :one
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+  )
+  AND (
+    user_id = $2::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility IN ('public', 'unlisted')
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $2::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $2::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $2::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > $3::timestamp)
+      )
+    )
+  )
 `
 
-func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpByID, id)
+type GetChirpByIDParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.UUID
+	Now      time.Time
+}
+
+func (q *Queries) GetChirpByID(ctx context.Context, arg GetChirpByIDParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByID, arg.ID, arg.ViewerID, arg.Now)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -105,19 +255,136 @@ func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
 const getChirpsByAuthor = `-- name: GetChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $2::uuid AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    user_id = $2::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility = 'public'
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $2::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = $2::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = $2::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > $3::timestamp)
+      )
+    )
+  )
 ORDER BY created_at ASC
 `
 
-func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, userID)
+type GetChirpsByAuthorParams struct {
+	UserID   uuid.UUID
+	ViewerID uuid.UUID
+	Now      time.Time
+}
+
+func (q *Queries) GetChirpsByAuthor(ctx context.Context, arg GetChirpsByAuthorParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, arg.UserID, arg.ViewerID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirpByID = `-- name: GetDeletedChirpByID :one
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) GetDeletedChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedChirpByID, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getDeletedChirpsByAuthor = `-- name: GetDeletedChirpsByAuthor :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE user_id = $1 AND deleted_at >= $2::timestamp
+ORDER BY deleted_at DESC
+`
+
+type GetDeletedChirpsByAuthorParams struct {
+	UserID       uuid.UUID
+	DeletedSince time.Time
+}
+
+func (q *Queries) GetDeletedChirpsByAuthor(ctx context.Context, arg GetDeletedChirpsByAuthorParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedChirpsByAuthor, arg.UserID, arg.DeletedSince)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +398,9 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -144,3 +414,71 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const hideChirp = `-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW(), updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, hideChirp, id)
+	return err
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < $1::timestamp
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = $1::timestamp
+WHERE id = $2 AND deleted_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+`
+
+type RestoreChirpParams struct {
+	Now time.Time
+	ID  uuid.UUID
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.Now, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = $1::timestamp, updated_at = $1::timestamp
+WHERE id = $2 AND deleted_at IS NULL
+`
+
+type SoftDeleteChirpParams struct {
+	Now time.Time
+	ID  uuid.UUID
+}
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, arg.Now, arg.ID)
+	return err
+}
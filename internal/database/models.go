@@ -6,17 +6,88 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	DeletedAt  sql.NullTime
+	Visibility string
+	HiddenAt   sql.NullTime
+}
+
+type ChirpMention struct {
+	ChirpID uuid.UUID
+	UserID  uuid.UUID
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type FollowRequest struct {
+	RequesterID uuid.UUID
+	TargetID    uuid.UUID
+	CreatedAt   time.Time
+}
+
+type KeywordFilter struct {
 	ID        uuid.UUID
+	UserID    uuid.UUID
+	Phrase    string
+	WholeWord bool
+	Action    string
+	ExpiresAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type ModerationAction struct {
+	ID            uuid.UUID
+	ModeratorID   uuid.NullUUID
+	Action        string
+	ReportID      uuid.NullUUID
+	TargetUserID  uuid.NullUUID
+	TargetChirpID uuid.NullUUID
+	Reason        string
+	CreatedAt     time.Time
+	ExpiresAt     sql.NullTime
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type OutboxEvent struct {
+	ID           uuid.UUID
+	EventType    string
+	UserID       uuid.NullUUID
+	Payload      json.RawMessage
+	CreatedAt    time.Time
+	DispatchedAt sql.NullTime
+}
+
+type ProfanityWord struct {
+	Word      string
+	Action    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
 }
 
 type RefreshToken struct {
@@ -28,11 +99,86 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Report struct {
+	ID            uuid.UUID
+	ReporterID    uuid.NullUUID
+	TargetUserID  uuid.UUID
+	TargetChirpID uuid.NullUUID
+	Category      string
+	Details       string
+	Status        string
+	ResolvedBy    uuid.NullUUID
+	ResolvedAt    sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Setting struct {
+	Key       string
+	Value     json.RawMessage
+	UpdatedAt time.Time
+}
+
+type Subscription struct {
+	UserID           uuid.UUID
+	Plan             string
+	Status           string
+	CurrentPeriodEnd time.Time
+	GracePeriodEnd   sql.NullTime
+	CanceledAt       sql.NullTime
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
 type User struct {
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Email             string
+	HashedPassword    string
+	IsChirpyRed       bool
+	IsPrivate         bool
+	SuspendedAt       sql.NullTime
+	Role              string
+	SuspendedUntil    sql.NullTime
+	ShadowBannedAt    sql.NullTime
+	ShadowBannedUntil sql.NullTime
+}
+
+type WebhookDelivery struct {
 	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	EventID        uuid.UUID
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	DeliveredAt    sql.NullTime
+}
+
+type WebhookEndpoint struct {
+	ID        uuid.UUID
+	UserID    uuid.NullUUID
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookEvent struct {
+	ID          uuid.UUID
+	Provider    string
+	EventID     string
+	EventType   string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	LastError   sql.NullString
+	ReceivedAt  time.Time
+	ProcessedAt sql.NullTime
+	ClaimedAt   time.Time
 }
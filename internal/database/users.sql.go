@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,7 +22,7 @@ VALUES (
     $1,
     $2
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_private, suspended_at, role, suspended_until, shadow_banned_at, shadow_banned_until
 `
 
 type CreateUserParams struct {
@@ -39,6 +40,12 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsPrivate,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.SuspendedUntil,
+		&i.ShadowBannedAt,
+		&i.ShadowBannedUntil,
 	)
 	return i, err
 }
@@ -52,8 +59,19 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const downgradeUserFromChirpyRed = `-- name: DowngradeUserFromChirpyRed :exec
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DowngradeUserFromChirpyRed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, downgradeUserFromChirpyRed, id)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_private, suspended_at, role, suspended_until, shadow_banned_at, shadow_banned_until FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -66,10 +84,186 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsPrivate,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.SuspendedUntil,
+		&i.ShadowBannedAt,
+		&i.ShadowBannedUntil,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_private, suspended_at, role, suspended_until, shadow_banned_at, shadow_banned_until FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsPrivate,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.SuspendedUntil,
+		&i.ShadowBannedAt,
+		&i.ShadowBannedUntil,
+	)
+	return i, err
+}
+
+const setUserPrivacy = `-- name: SetUserPrivacy :one
+UPDATE users
+SET
+  is_private = $2,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, is_chirpy_red, is_private
+`
+
+type SetUserPrivacyParams struct {
+	ID        uuid.UUID
+	IsPrivate bool
+}
+
+type SetUserPrivacyRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Email       string
+	IsChirpyRed bool
+	IsPrivate   bool
+}
+
+func (q *Queries) SetUserPrivacy(ctx context.Context, arg SetUserPrivacyParams) (SetUserPrivacyRow, error) {
+	row := q.db.QueryRowContext(ctx, setUserPrivacy, arg.ID, arg.IsPrivate)
+	var i SetUserPrivacyRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.IsChirpyRed,
+		&i.IsPrivate,
 	)
 	return i, err
 }
 
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET
+  role = $2,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING id, email, role
+`
+
+type SetUserRoleParams struct {
+	ID   uuid.UUID
+	Role string
+}
+
+type SetUserRoleRow struct {
+	ID    uuid.UUID
+	Email string
+	Role  string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (SetUserRoleRow, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.ID, arg.Role)
+	var i SetUserRoleRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+	)
+	return i, err
+}
+
+const shadowBanUser = `-- name: ShadowBanUser :execrows
+UPDATE users
+SET
+  shadow_banned_at = NOW(),
+  shadow_banned_until = $2,
+  updated_at = NOW()
+WHERE id = $1
+`
+
+type ShadowBanUserParams struct {
+	ID                uuid.UUID
+	ShadowBannedUntil sql.NullTime
+}
+
+func (q *Queries) ShadowBanUser(ctx context.Context, arg ShadowBanUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, shadowBanUser, arg.ID, arg.ShadowBannedUntil)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const suspendUser = `-- name: SuspendUser :execrows
+UPDATE users
+SET
+  suspended_at = NOW(),
+  suspended_until = $2,
+  updated_at = NOW()
+WHERE id = $1
+`
+
+type SuspendUserParams struct {
+	ID             uuid.UUID
+	SuspendedUntil sql.NullTime
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, suspendUser, arg.ID, arg.SuspendedUntil)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unshadowBanUser = `-- name: UnshadowBanUser :execrows
+UPDATE users
+SET
+  shadow_banned_at = NULL,
+  shadow_banned_until = NULL,
+  updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UnshadowBanUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unshadowBanUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :execrows
+UPDATE users
+SET
+  suspended_at = NULL,
+  suspended_until = NULL,
+  updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unsuspendUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users 
 SET 
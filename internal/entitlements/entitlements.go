@@ -0,0 +1,124 @@
+package entitlements
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    PlanFree      = "free"
+    PlanChirpyRed = "chirpy_red"
+)
+
+// Limits describes what a plan is allowed to do. A zero EditWindowSeconds or
+// MaxAPIKeys means the feature is unavailable on the plan.
+type Limits struct {
+    MaxChirpLength    int   `json:"max_chirp_length"`
+    EditWindowSeconds int   `json:"edit_window_seconds"`
+    MaxMediaBytes     int64 `json:"max_media_bytes"`
+    Scheduling        bool  `json:"scheduling"`
+    MaxAPIKeys        int   `json:"max_api_keys"`
+}
+
+func (l Limits) EditWindow() time.Duration {
+    return time.Duration(l.EditWindowSeconds) * time.Second
+}
+
+// Plans maps plan names to their limits. It must always contain PlanFree,
+// which is used for users without a subscription and for unknown plans.
+type Plans map[string]Limits
+
+func DefaultPlans() Plans {
+    return Plans{
+        PlanFree: {
+            MaxChirpLength: 140,
+            MaxMediaBytes:  5 << 20,
+            MaxAPIKeys:     1,
+        },
+        PlanChirpyRed: {
+            MaxChirpLength:    1000,
+            EditWindowSeconds: 3600,
+            MaxMediaBytes:     50 << 20,
+            Scheduling:        true,
+            MaxAPIKeys:        10,
+        },
+    }
+}
+
+func (p Plans) Validate() error {
+    var problems []string
+    if _, ok := p[PlanFree]; !ok {
+        problems = append(problems, "the free plan must be defined")
+    }
+
+    names := make([]string, 0, len(p))
+    for name := range p {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    for _, name := range names {
+        limits := p[name]
+        if name == "" {
+            problems = append(problems, "plan names cannot be empty")
+        }
+        if limits.MaxChirpLength <= 0 {
+            problems = append(problems, fmt.Sprintf("%s: max_chirp_length must be positive", name))
+        }
+        if limits.EditWindowSeconds < 0 || limits.MaxMediaBytes < 0 || limits.MaxAPIKeys < 0 {
+            problems = append(problems, fmt.Sprintf("%s: limits cannot be negative", name))
+        }
+    }
+
+    if len(problems) > 0 {
+        return errors.New(strings.Join(problems, "; "))
+    }
+    return nil
+}
+
+// For returns the limits for plan, falling back to the free plan.
+func (p Plans) For(plan string) Limits {
+    if limits, ok := p[plan]; ok {
+        return limits
+    }
+    return p[PlanFree]
+}
+
+// Registry holds the current plans and can be reloaded while the server is
+// running.
+type Registry struct {
+    mu    sync.RWMutex
+    plans Plans
+}
+
+func NewRegistry(plans Plans) *Registry {
+    return &Registry{plans: plans}
+}
+
+// Plans returns a copy of the current plans.
+func (r *Registry) Plans() Plans {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    plans := make(Plans, len(r.plans))
+    for name, limits := range r.plans {
+        plans[name] = limits
+    }
+    return plans
+}
+
+func (r *Registry) For(plan string) Limits {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.plans.For(plan)
+}
+
+func (r *Registry) Load(plans Plans) {
+    r.mu.Lock()
+    r.plans = plans
+    r.mu.Unlock()
+}
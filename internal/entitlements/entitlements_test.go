@@ -0,0 +1,43 @@
+package entitlements
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPlansAreValid(t *testing.T) {
+    assert.NoError(t, DefaultPlans().Validate())
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+    plans := Plans{
+        PlanChirpyRed: {MaxChirpLength: 0, MaxAPIKeys: -1},
+    }
+
+    err := plans.Validate()
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "free plan")
+    assert.Contains(t, err.Error(), "max_chirp_length")
+    assert.Contains(t, err.Error(), "negative")
+}
+
+func TestForFallsBackToFree(t *testing.T) {
+    plans := DefaultPlans()
+
+    assert.Equal(t, 140, plans.For(PlanFree).MaxChirpLength)
+    assert.Equal(t, 1000, plans.For(PlanChirpyRed).MaxChirpLength)
+    assert.Equal(t, plans[PlanFree], plans.For("enterprise"))
+}
+
+func TestRegistryReturnsCopy(t *testing.T) {
+    registry := NewRegistry(DefaultPlans())
+
+    plans := registry.Plans()
+    plans[PlanFree] = Limits{MaxChirpLength: 1}
+
+    assert.Equal(t, 140, registry.For(PlanFree).MaxChirpLength)
+
+    registry.Load(plans)
+    assert.Equal(t, 1, registry.For(PlanFree).MaxChirpLength)
+}
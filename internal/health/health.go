@@ -0,0 +1,114 @@
+// Package health runs readiness checks and collects their results into a
+// report suitable for a load balancer or an operator.
+package health
+
+import (
+    "context"
+    "database/sql"
+    "net"
+    "sync"
+    "time"
+)
+
+type Status string
+
+const (
+    StatusOK      Status = "ok"
+    StatusFailing Status = "failing"
+)
+
+// Check is a single readiness probe. Run may return a detail value, such as
+// a version number, that is included in the report whether or not it fails.
+type Check struct {
+    Name string
+    // Critical checks make the service unready when they fail; the others
+    // are only reported.
+    Critical bool
+    Run      func(ctx context.Context) (any, error)
+}
+
+type Result struct {
+    Name       string `json:"name"`
+    Status     Status `json:"status"`
+    Critical   bool   `json:"critical"`
+    DurationMS int64  `json:"duration_ms"`
+    Detail     any    `json:"detail,omitempty"`
+    Error      string `json:"error,omitempty"`
+}
+
+type Report struct {
+    Status Status   `json:"status"`
+    Checks []Result `json:"checks"`
+}
+
+// Run runs every check concurrently, each bounded by timeout, and returns
+// the results in the order the checks were given. The report is failing if
+// any critical check failed.
+func Run(ctx context.Context, timeout time.Duration, checks []Check) Report {
+    results := make([]Result, len(checks))
+
+    var wg sync.WaitGroup
+    for i, check := range checks {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            results[i] = run(ctx, timeout, check)
+        }()
+    }
+    wg.Wait()
+
+    report := Report{Status: StatusOK, Checks: results}
+    for _, result := range results {
+        if result.Critical && result.Status != StatusOK {
+            report.Status = StatusFailing
+        }
+    }
+    return report
+}
+
+func run(ctx context.Context, timeout time.Duration, check Check) Result {
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    start := time.Now()
+    detail, err := check.Run(ctx)
+    result := Result{
+        Name:       check.Name,
+        Status:     StatusOK,
+        Critical:   check.Critical,
+        DurationMS: time.Since(start).Milliseconds(),
+        Detail:     detail,
+    }
+    if err != nil {
+        result.Status = StatusFailing
+        result.Error = err.Error()
+    }
+    return result
+}
+
+// Ping checks that db accepts connections.
+func Ping(name string, db *sql.DB) Check {
+    return Check{
+        Name:     name,
+        Critical: true,
+        Run: func(ctx context.Context) (any, error) {
+            return nil, db.PingContext(ctx)
+        },
+    }
+}
+
+// Dial checks that a TCP connection can be opened to address. It suits
+// dependencies the service can run without, so the check is not critical.
+func Dial(name, address string) Check {
+    return Check{
+        Name: name,
+        Run: func(ctx context.Context) (any, error) {
+            var d net.Dialer
+            conn, err := d.DialContext(ctx, "tcp", address)
+            if err != nil {
+                return nil, err
+            }
+            return nil, conn.Close()
+        },
+    }
+}
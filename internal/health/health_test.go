@@ -0,0 +1,66 @@
+package health
+
+import (
+    "context"
+    "errors"
+    "net"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestRunKeepsOrderAndDetails(t *testing.T) {
+    report := Run(context.Background(), time.Second, []Check{
+        {Name: "slow", Critical: true, Run: func(ctx context.Context) (any, error) {
+            time.Sleep(20 * time.Millisecond)
+            return 18, nil
+        }},
+        {Name: "fast", Critical: true, Run: func(ctx context.Context) (any, error) { return nil, nil }},
+    })
+
+    assert.Equal(t, StatusOK, report.Status)
+    assert.Equal(t, "slow", report.Checks[0].Name)
+    assert.Equal(t, 18, report.Checks[0].Detail)
+    assert.Equal(t, "fast", report.Checks[1].Name)
+}
+
+func TestRunFailsOnlyOnCriticalChecks(t *testing.T) {
+    failing := func(ctx context.Context) (any, error) { return nil, errors.New("down") }
+
+    report := Run(context.Background(), time.Second, []Check{{Name: "exporter", Run: failing}})
+    assert.Equal(t, StatusOK, report.Status)
+    assert.Equal(t, StatusFailing, report.Checks[0].Status)
+    assert.Equal(t, "down", report.Checks[0].Error)
+
+    report = Run(context.Background(), time.Second, []Check{{Name: "database", Critical: true, Run: failing}})
+    assert.Equal(t, StatusFailing, report.Status)
+}
+
+func TestRunTimesOutSlowChecks(t *testing.T) {
+    report := Run(context.Background(), 10*time.Millisecond, []Check{{
+        Name:     "hung",
+        Critical: true,
+        Run: func(ctx context.Context) (any, error) {
+            <-ctx.Done()
+            return nil, ctx.Err()
+        },
+    }})
+
+    assert.Equal(t, StatusFailing, report.Status)
+    assert.Contains(t, report.Checks[0].Error, "deadline exceeded")
+}
+
+func TestDial(t *testing.T) {
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    assert.NoError(t, err)
+    address := listener.Addr().String()
+
+    result := Run(context.Background(), time.Second, []Check{Dial("collector", address)})
+    assert.Equal(t, StatusOK, result.Checks[0].Status)
+
+    listener.Close()
+    result = Run(context.Background(), time.Second, []Check{Dial("collector", address)})
+    assert.Equal(t, StatusFailing, result.Checks[0].Status)
+    assert.Equal(t, StatusOK, result.Status)
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+    "database/sql"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "chirpy"
+
+// Login results.
+const (
+    LoginSuccess            = "success"
+    LoginInvalidCredentials = "invalid_credentials"
+    LoginSuspended          = "suspended"
+)
+
+// Webhook directions and outcomes.
+const (
+    WebhookInbound  = "inbound"
+    WebhookOutbound = "outbound"
+
+    WebhookProcessed = "processed"
+    WebhookDuplicate = "duplicate"
+    WebhookRejected  = "rejected"
+    WebhookFailed    = "failed"
+    WebhookRetried   = "retried"
+    WebhookDead      = "dead"
+)
+
+// Metrics holds the server's Prometheus collectors on a private registry so
+// tests can create as many as they like.
+type Metrics struct {
+    registry *prometheus.Registry
+
+    Requests *prometheus.CounterVec
+    Duration *prometheus.HistogramVec
+    InFlight prometheus.Gauge
+    Logins   *prometheus.CounterVec
+    Webhooks *prometheus.CounterVec
+}
+
+func New() *Metrics {
+    m := &Metrics{
+        registry: prometheus.NewRegistry(),
+        Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "http_requests_total",
+            Help:      "HTTP requests by route, method and status code.",
+        }, []string{"route", "method", "code"}),
+        Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Name:      "http_request_duration_seconds",
+            Help:      "HTTP request latency by route and method.",
+            Buckets:   prometheus.DefBuckets,
+        }, []string{"route", "method"}),
+        InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+            Namespace: namespace,
+            Name:      "http_requests_in_flight",
+            Help:      "HTTP requests currently being served.",
+        }),
+        Logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "logins_total",
+            Help:      "Login attempts by result.",
+        }, []string{"result"}),
+        Webhooks: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "webhooks_total",
+            Help:      "Webhook deliveries by direction and outcome.",
+        }, []string{"direction", "outcome"}),
+    }
+
+    m.registry.MustRegister(
+        m.Requests,
+        m.Duration,
+        m.InFlight,
+        m.Logins,
+        m.Webhooks,
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+    )
+    return m
+}
+
+// RegisterDB exports connection pool statistics for db.
+func (m *Metrics) RegisterDB(db *sql.DB) {
+    m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+    return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records every request served by next. Wrap the ServeMux itself
+// so the route label is the matched pattern rather than the raw path, which
+// would give every chirp ID its own series.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        m.InFlight.Inc()
+        defer m.InFlight.Dec()
+
+        rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+        start := time.Now()
+        next.ServeHTTP(rec, r)
+
+        // ServeMux sets Pattern on the request it was given once it has
+        // picked a handler.
+        route := r.Pattern
+        if route == "" {
+            route = "unmatched"
+        }
+
+        m.Duration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+        m.Requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+    })
+}
+
+type statusRecorder struct {
+    http.ResponseWriter
+    status      int
+    wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+    if !r.wroteHeader {
+        r.status = status
+        r.wroteHeader = true
+    }
+    r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+    r.wroteHeader = true
+    return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+    return r.ResponseWriter
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareLabelsByPattern(t *testing.T) {
+    m := New()
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNotFound)
+    })
+    mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte("OK"))
+    })
+    handler := m.Middleware(mux)
+
+    for _, path := range []string{"/api/chirps/1", "/api/chirps/2", "/api/healthz", "/nope"} {
+        handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+    }
+
+    assert.Equal(t, 2.0, testutil.ToFloat64(m.Requests.WithLabelValues("GET /api/chirps/{chirpID}", "GET", "404")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.Requests.WithLabelValues("GET /api/healthz", "GET", "200")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.Requests.WithLabelValues("unmatched", "GET", "404")))
+    assert.Equal(t, 0.0, testutil.ToFloat64(m.InFlight))
+}
+
+func TestHandlerExposesTextFormat(t *testing.T) {
+    m := New()
+    m.Logins.WithLabelValues(LoginSuccess).Inc()
+    m.Webhooks.WithLabelValues(WebhookInbound, WebhookProcessed).Inc()
+
+    rec := httptest.NewRecorder()
+    m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+    body, _ := io.ReadAll(rec.Body)
+    assert.True(t, strings.Contains(string(body), `chirpy_logins_total{result="success"} 1`))
+    assert.True(t, strings.Contains(string(body), `chirpy_webhooks_total{direction="inbound",outcome="processed"} 1`))
+}
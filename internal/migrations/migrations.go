@@ -0,0 +1,103 @@
+// Package migrations applies the goose migrations embedded in the binary.
+package migrations
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io/fs"
+
+    "github.com/pressly/goose/v3"
+    "github.com/pressly/goose/v3/lock"
+)
+
+var (
+    // ErrPending means the database is missing migrations this build has.
+    ErrPending = errors.New("migrations pending")
+    // ErrSchemaTooNew means the database was migrated by a newer build.
+    ErrSchemaTooNew = errors.New("database schema is newer than this build")
+)
+
+// Migrator runs the migrations found in a filesystem against one database.
+type Migrator struct {
+    provider *goose.Provider
+    latest   int64
+}
+
+// New returns a Migrator for the SQL migrations in fsys. Up and Down hold a
+// Postgres advisory lock for their whole run, so replicas that start at the
+// same time take turns instead of racing to apply the same migration.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+    // Wait up to five minutes for another replica to finish migrating.
+    locker, err := lock.NewPostgresSessionLocker(lock.WithLockTimeout(5, 60))
+    if err != nil {
+        return nil, err
+    }
+    return newMigrator(goose.DialectPostgres, db, fsys, goose.WithSessionLocker(locker))
+}
+
+// NewSQLite returns a Migrator for an SQLite database. SQLite has no
+// advisory locks, and only one process uses the file, so it runs without one.
+func NewSQLite(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+    return newMigrator(goose.DialectSQLite3, db, fsys)
+}
+
+func newMigrator(dialect goose.Dialect, db *sql.DB, fsys fs.FS, opts ...goose.ProviderOption) (*Migrator, error) {
+    opts = append(opts, goose.WithDisableGlobalRegistry(true))
+    provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+    if err != nil {
+        return nil, err
+    }
+
+    sources := provider.ListSources()
+    return &Migrator{
+        provider: provider,
+        latest:   sources[len(sources)-1].Version,
+    }, nil
+}
+
+// Latest is the version of the newest migration in the binary.
+func (m *Migrator) Latest() int64 {
+    return m.latest
+}
+
+// Version is the newest migration version applied to the database. It does
+// not wait for the migration lock, so it is safe to call from health checks
+// while another replica migrates.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+    current, _, err := m.provider.GetVersions(ctx)
+    return current, err
+}
+
+// Check compares the database with the binary. It returns the database
+// version and an error wrapping ErrPending or ErrSchemaTooNew when they
+// differ.
+func (m *Migrator) Check(ctx context.Context) (int64, error) {
+    current, err := m.Version(ctx)
+    if err != nil {
+        return 0, err
+    }
+    switch {
+    case current < m.latest:
+        return current, fmt.Errorf("%w: database at %d, build expects %d", ErrPending, current, m.latest)
+    case current > m.latest:
+        return current, fmt.Errorf("%w: database at %d, build expects %d", ErrSchemaTooNew, current, m.latest)
+    }
+    return current, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+    return m.provider.Up(ctx)
+}
+
+// Down rolls back the newest applied migration.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+    return m.provider.Down(ctx)
+}
+
+// Status lists every migration in the binary with whether it is applied.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+    return m.provider.Status(ctx)
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "testing"
+    "testing/fstest"
+
+    _ "github.com/lib/pq"
+    _ "github.com/mattn/go-sqlite3"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestNewFindsLatestVersion(t *testing.T) {
+    // lib/pq connects lazily, so no database is needed to read the sources.
+    db, err := sql.Open("postgres", "postgres://localhost/unused")
+    assert.NoError(t, err)
+    defer db.Close()
+
+    fsys := fstest.MapFS{
+        "001_users.sql":  {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+        "010_chirps.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+        "002_tokens.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+    }
+
+    m, err := New(db, fsys)
+    assert.NoError(t, err)
+    assert.Equal(t, int64(10), m.Latest())
+}
+
+func TestNewRejectsEmptyFS(t *testing.T) {
+    db, err := sql.Open("postgres", "postgres://localhost/unused")
+    assert.NoError(t, err)
+    defer db.Close()
+
+    _, err = New(db, fstest.MapFS{})
+    assert.Error(t, err)
+}
+
+func TestSQLiteUpAndCheck(t *testing.T) {
+    db, err := sql.Open("sqlite3", ":memory:")
+    assert.NoError(t, err)
+    defer db.Close()
+    // Every connection to :memory: is a new database.
+    db.SetMaxOpenConns(1)
+
+    fsys := fstest.MapFS{
+        "001_users.sql": {Data: []byte("-- +goose Up\nCREATE TABLE users (id TEXT PRIMARY KEY);\n-- +goose Down\nDROP TABLE users;\n")},
+    }
+    m, err := NewSQLite(db, fsys)
+    assert.NoError(t, err)
+
+    ctx := context.Background()
+    _, err = m.Check(ctx)
+    assert.True(t, errors.Is(err, ErrPending))
+
+    results, err := m.Up(ctx)
+    assert.NoError(t, err)
+    assert.Len(t, results, 1)
+
+    version, err := m.Check(ctx)
+    assert.NoError(t, err)
+    assert.Equal(t, int64(1), version)
+}
@@ -0,0 +1,65 @@
+package moderation
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// Keyword is a user's personal muted word or phrase. Unlike the server-wide
+// Filter it never rewrites a chirp; it only tells the caller which keyword a
+// chirp matched so the chirp can be hidden or collapsed for that user.
+type Keyword struct {
+    Phrase    string
+    WholeWord bool
+}
+
+// MatchKeyword returns the index of the first keyword found in body, or -1.
+// Matching is case- and diacritic-insensitive. Whole-word keywords only match
+// when not directly preceded or followed by a letter or digit.
+func MatchKeyword(body string, keywords []Keyword) int {
+    folded := Fold(body)
+    for i, keyword := range keywords {
+        phrase := Fold(strings.TrimSpace(keyword.Phrase))
+        if phrase == "" {
+            continue
+        }
+        if containsPhrase(folded, phrase, keyword.WholeWord) {
+            return i
+        }
+    }
+    return -1
+}
+
+func containsPhrase(s, phrase string, wholeWord bool) bool {
+    offset := 0
+    for {
+        idx := strings.Index(s[offset:], phrase)
+        if idx < 0 {
+            return false
+        }
+        start := offset + idx
+        end := start + len(phrase)
+        if !wholeWord || (isBoundary(s, start, true) && isBoundary(s, end, false)) {
+            return true
+        }
+        _, size := utf8.DecodeRuneInString(s[start:])
+        offset = start + size
+    }
+}
+
+func isBoundary(s string, i int, before bool) bool {
+    var r rune
+    if before {
+        if i == 0 {
+            return true
+        }
+        r, _ = utf8.DecodeLastRuneInString(s[:i])
+    } else {
+        if i == len(s) {
+            return true
+        }
+        r, _ = utf8.DecodeRuneInString(s[i:])
+    }
+    return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,26 @@
+package moderation
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestMatchKeywordSubstring(t *testing.T) {
+    keywords := []Keyword{{Phrase: "spoiler"}}
+    assert.Equal(t, 0, MatchKeyword("Huge SPOILERS below", keywords))
+    assert.Equal(t, -1, MatchKeyword("nothing to see", keywords))
+}
+
+func TestMatchKeywordWholeWord(t *testing.T) {
+    keywords := []Keyword{{Phrase: "cat", WholeWord: true}}
+    assert.Equal(t, 0, MatchKeyword("my cat, again", keywords))
+    assert.Equal(t, -1, MatchKeyword("concatenate", keywords))
+    assert.Equal(t, 0, MatchKeyword("concatenate the cat", keywords))
+}
+
+func TestMatchKeywordPhraseAndDiacritics(t *testing.T) {
+    keywords := []Keyword{{Phrase: "season finale"}, {Phrase: "Pokémon", WholeWord: true}}
+    assert.Equal(t, 0, MatchKeyword("that Season Finale though", keywords))
+    assert.Equal(t, 1, MatchKeyword("pokemon!", keywords))
+}
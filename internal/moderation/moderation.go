@@ -0,0 +1,206 @@
+package moderation
+
+import (
+    "strings"
+    "sync"
+    "unicode"
+
+    "golang.org/x/text/unicode/norm"
+)
+
+type Action string
+
+const (
+    ActionMask   Action = "mask"
+    ActionReject Action = "reject"
+    ActionFlag   Action = "flag"
+)
+
+const mask = "****"
+
+func (a Action) Valid() bool {
+    switch a {
+    case ActionMask, ActionReject, ActionFlag:
+        return true
+    }
+    return false
+}
+
+type Rule struct {
+    Word   string
+    Action Action
+}
+
+type Match struct {
+    Word   string `json:"word"`
+    Action Action `json:"action"`
+}
+
+type Result struct {
+    Body     string
+    Rejected bool
+    Flagged  bool
+    Matches  []Match
+}
+
+// DefaultRules is the word list migration 010 seeds Postgres with, for the
+// backends that have no profanity_words table.
+func DefaultRules() []Rule {
+    return []Rule{
+        {Word: "kerfuffle", Action: ActionMask},
+        {Word: "sharbert", Action: ActionMask},
+        {Word: "fornax", Action: ActionMask},
+    }
+}
+
+// Filter matches chirp bodies against a word list. It is safe for concurrent
+// use, and Load swaps the list in place so edits apply without a restart.
+type Filter struct {
+    mu    sync.RWMutex
+    rules map[string]Rule
+}
+
+func NewFilter(rules []Rule) *Filter {
+    f := &Filter{}
+    f.Load(rules)
+    return f
+}
+
+func (f *Filter) Load(rules []Rule) {
+    compiled := make(map[string]Rule, len(rules))
+    for _, rule := range rules {
+        key := Normalize(rule.Word)
+        if key == "" {
+            continue
+        }
+        compiled[key] = rule
+    }
+
+    f.mu.Lock()
+    f.rules = compiled
+    f.mu.Unlock()
+}
+
+// Check masks the words listed with ActionMask and reports which rules
+// fired. Flagged and rejected words are left as written, as is the
+// whitespace and punctuation around a match.
+func (f *Filter) Check(body string) Result {
+    f.mu.RLock()
+    rules := f.rules
+    f.mu.RUnlock()
+
+    result := Result{}
+    var out strings.Builder
+    last := 0
+
+    for _, span := range tokenSpans(body) {
+        start, end, rule, ok := matchToken(body, span[0], span[1], rules)
+        if !ok {
+            continue
+        }
+
+        result.Matches = append(result.Matches, Match{Word: rule.Word, Action: rule.Action})
+        switch rule.Action {
+        case ActionReject:
+            result.Rejected = true
+        case ActionFlag:
+            result.Flagged = true
+        case ActionMask:
+            out.WriteString(body[last:start])
+            out.WriteString(mask)
+            last = end
+        }
+    }
+
+    out.WriteString(body[last:])
+    result.Body = out.String()
+    return result
+}
+
+// Normalize folds a word to the form rules are compared in: Fold, plus
+// common leetspeak substitutions undone.
+func Normalize(word string) string {
+    return strings.Map(func(r rune) rune {
+        if folded, ok := leet[r]; ok {
+            return folded
+        }
+        return r
+    }, Fold(word))
+}
+
+// Fold applies compatibility decomposition, drops diacritics and lowers case.
+func Fold(s string) string {
+    var b strings.Builder
+    for _, r := range norm.NFKD.String(s) {
+        if unicode.Is(unicode.Mn, r) {
+            continue
+        }
+        b.WriteRune(unicode.ToLower(r))
+    }
+    return b.String()
+}
+
+var leet = map[rune]rune{
+    '0': 'o',
+    '1': 'i',
+    '3': 'e',
+    '4': 'a',
+    '5': 's',
+    '7': 't',
+    '@': 'a',
+    '$': 's',
+    '!': 'i',
+    '|': 'l',
+}
+
+// tokenSpans returns the byte offsets of each whitespace-separated token.
+func tokenSpans(s string) [][2]int {
+    var spans [][2]int
+    start := -1
+    for i, r := range s {
+        if unicode.IsSpace(r) {
+            if start >= 0 {
+                spans = append(spans, [2]int{start, i})
+                start = -1
+            }
+            continue
+        }
+        if start < 0 {
+            start = i
+        }
+    }
+    if start >= 0 {
+        spans = append(spans, [2]int{start, len(s)})
+    }
+    return spans
+}
+
+// matchToken looks for a rule inside s[start:end]. Surrounding punctuation is
+// trimmed first; since "!" and "$" double as leetspeak, it tries the token
+// with only letters and digits kept at the edges, then with leet characters
+// kept as well, so both "fornax!" and "$harbert" match.
+func matchToken(s string, start, end int, rules map[string]Rule) (int, int, Rule, bool) {
+    isCore := func(r rune) bool {
+        return unicode.IsLetter(r) || unicode.IsDigit(r)
+    }
+    isLeetCore := func(r rune) bool {
+        _, ok := leet[r]
+        return isCore(r) || ok
+    }
+
+    for _, keep := range []func(rune) bool{isCore, isLeetCore} {
+        token := s[start:end]
+        trimmedLeft := strings.TrimLeftFunc(token, func(r rune) bool { return !keep(r) })
+        trimmed := strings.TrimRightFunc(trimmedLeft, func(r rune) bool { return !keep(r) })
+        if trimmed == "" {
+            continue
+        }
+
+        if rule, ok := rules[Normalize(trimmed)]; ok {
+            coreStart := start + len(token) - len(trimmedLeft)
+            return coreStart, coreStart + len(trimmed), rule, true
+        }
+    }
+
+    return 0, 0, Rule{}, false
+}
@@ -0,0 +1,67 @@
+package moderation
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func defaultFilter() *Filter {
+    return NewFilter(DefaultRules())
+}
+
+func TestCheckMasksWholeWords(t *testing.T) {
+    result := defaultFilter().Check("This is a kerfuffle opinion I need to share with the world")
+    assert.Equal(t, "This is a **** opinion I need to share with the world", result.Body)
+    assert.False(t, result.Rejected)
+    assert.False(t, result.Flagged)
+    assert.Len(t, result.Matches, 1)
+}
+
+func TestCheckStripsPunctuation(t *testing.T) {
+    result := defaultFilter().Check("What a Kerfuffle! Sharbert, really?")
+    assert.Equal(t, "What a ****! ****, really?", result.Body)
+}
+
+func TestCheckPreservesSpacing(t *testing.T) {
+    result := defaultFilter().Check("  fornax\tand   sharbert \n")
+    assert.Equal(t, "  ****\tand   **** \n", result.Body)
+}
+
+func TestCheckFoldsDiacriticsAndLeetspeak(t *testing.T) {
+    filter := defaultFilter()
+    assert.Equal(t, "****", filter.Check("kérfüffle").Body)
+    assert.Equal(t, "****", filter.Check("K3RFUFFL3").Body)
+    assert.Equal(t, "****", filter.Check("$h4rb3rt").Body)
+    assert.Equal(t, "****", filter.Check("ｆｏｒｎａｘ").Body)
+}
+
+func TestCheckLeavesSubstringsAlone(t *testing.T) {
+    result := defaultFilter().Check("kerfuffles and sharberts")
+    assert.Equal(t, "kerfuffles and sharberts", result.Body)
+    assert.Empty(t, result.Matches)
+}
+
+func TestCheckActions(t *testing.T) {
+    filter := NewFilter([]Rule{
+        {Word: "spoiler", Action: ActionFlag},
+        {Word: "slur", Action: ActionReject},
+    })
+
+    flagged := filter.Check("big spoiler ahead")
+    assert.True(t, flagged.Flagged)
+    assert.False(t, flagged.Rejected)
+    // Flagging queues the chirp for review without changing what it says.
+    assert.Equal(t, "big spoiler ahead", flagged.Body)
+
+    rejected := filter.Check("a slur")
+    assert.True(t, rejected.Rejected)
+    assert.Equal(t, []Match{{Word: "slur", Action: ActionReject}}, rejected.Matches)
+}
+
+func TestLoadReplacesRules(t *testing.T) {
+    filter := defaultFilter()
+    filter.Load([]Rule{{Word: "gizmo", Action: ActionMask}})
+
+    assert.Equal(t, "kerfuffle ****", filter.Check("kerfuffle gizmo").Body)
+}
@@ -0,0 +1,86 @@
+package outbound
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/netip"
+    "net/url"
+    "syscall"
+)
+
+// ErrUnroutable is returned for endpoints on loopback, private, link-local
+// or other non-public addresses. Posting there would let any user make the
+// server probe its own network.
+var ErrUnroutable = errors.New("address is not publicly routable")
+
+// reserved lists ranges the netip predicates don't cover.
+var reserved = []netip.Prefix{
+    netip.MustParsePrefix("0.0.0.0/8"),
+    netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+    netip.MustParsePrefix("192.0.0.0/24"),
+    netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+    netip.MustParsePrefix("240.0.0.0/4"),
+    netip.MustParsePrefix("64:ff9b::/96"), // NAT64 can reach any IPv4 address
+}
+
+// Routable reports whether addr is a public unicast address. Link-local
+// covers the 169.254.169.254 cloud metadata service.
+func Routable(addr netip.Addr) bool {
+    addr = addr.Unmap()
+    if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+        addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+        addr.IsMulticast() {
+        return false
+    }
+    for _, prefix := range reserved {
+        if prefix.Contains(addr) {
+            return false
+        }
+    }
+    return true
+}
+
+// CheckURL validates an endpoint URL when it is registered: it must be
+// absolute, use https (or http, unless requireHTTPS is set), and its host
+// must resolve only to routable addresses. The sender checks again when it
+// connects, since DNS can change after registration.
+func CheckURL(ctx context.Context, rawURL string, requireHTTPS bool) (*url.URL, error) {
+    parsed, err := url.Parse(rawURL)
+    switch {
+    case err != nil || parsed.Host == "" || parsed.Hostname() == "":
+        return nil, errors.New("URL must be an absolute http or https URL")
+    case requireHTTPS && parsed.Scheme != "https":
+        return nil, errors.New("URL must use https")
+    case parsed.Scheme != "https" && parsed.Scheme != "http":
+        return nil, errors.New("URL must be an absolute http or https URL")
+    }
+
+    addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+    if err != nil {
+        return nil, fmt.Errorf("URL host %q could not be resolved", parsed.Hostname())
+    }
+    for _, addr := range addrs {
+        if !Routable(addr) {
+            return nil, fmt.Errorf("URL host %q: %w", parsed.Hostname(), ErrUnroutable)
+        }
+    }
+    return parsed, nil
+}
+
+// dialControl refuses connections to addresses allowed rejects. It runs
+// after DNS resolution, on the address actually dialed, so redirects and
+// DNS records changed after registration are covered too.
+func dialControl(allowed func(netip.Addr) bool) func(network, address string, c syscall.RawConn) error {
+    return func(network, address string, c syscall.RawConn) error {
+        addrPort, err := netip.ParseAddrPort(address)
+        if err != nil {
+            return err
+        }
+        if !allowed(addrPort.Addr()) {
+            return fmt.Errorf("dial %s: %w", address, ErrUnroutable)
+        }
+        return nil
+    }
+}
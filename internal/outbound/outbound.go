@@ -0,0 +1,130 @@
+package outbound
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/netip"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/tracing"
+    "github.com/google/uuid"
+)
+
+const (
+    SignatureHeader = "X-Chirpy-Signature"
+    TimestampHeader = "X-Chirpy-Timestamp"
+    EventHeader     = "X-Chirpy-Event"
+    DeliveryHeader  = "X-Chirpy-Delivery"
+)
+
+const (
+    // MaxAttempts is how many times a delivery is tried before it is
+    // dead-lettered.
+    MaxAttempts = 8
+
+    baseBackoff = 30 * time.Second
+    maxBackoff  = 6 * time.Hour
+)
+
+// Backoff returns how long to wait after the given number of failed
+// attempts: 30s, 1m, 2m, ... capped at six hours.
+func Backoff(attempts int) time.Duration {
+    if attempts < 1 {
+        attempts = 1
+    }
+    delay := baseBackoff
+    for i := 1; i < attempts; i++ {
+        delay *= 2
+        if delay >= maxBackoff {
+            return maxBackoff
+        }
+    }
+    return delay
+}
+
+// Message is a single event addressed to one endpoint.
+type Message struct {
+    DeliveryID uuid.UUID
+    EventID    uuid.UUID
+    EventType  string
+    CreatedAt  time.Time
+    Payload    json.RawMessage
+}
+
+type envelope struct {
+    ID        uuid.UUID       `json:"id"`
+    Type      string          `json:"type"`
+    CreatedAt time.Time       `json:"created_at"`
+    Data      json.RawMessage `json:"data"`
+}
+
+// Sender posts signed messages to webhook endpoints.
+type Sender struct {
+    Client *http.Client
+}
+
+// NewSender returns a Sender that only connects to routable addresses.
+func NewSender(timeout time.Duration) *Sender {
+    return newSender(timeout, Routable)
+}
+
+func newSender(timeout time.Duration, allowed func(netip.Addr) bool) *Sender {
+    dialer := &net.Dialer{
+        Timeout: 30 * time.Second,
+        Control: dialControl(allowed),
+    }
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.DialContext = dialer.DialContext
+    // A proxy would be dialed instead of the endpoint, bypassing the check.
+    transport.Proxy = nil
+
+    return &Sender{Client: &http.Client{
+        Timeout:   timeout,
+        Transport: tracing.Transport(transport),
+    }}
+}
+
+// Send delivers msg to url, signed with secret using the same scheme Chirpy
+// verifies on inbound webhooks. It returns the response status code, or 0 if
+// no response was received, and an error unless the endpoint answered 2xx.
+func (s *Sender) Send(ctx context.Context, url, secret string, msg Message) (int, error) {
+    body, err := json.Marshal(envelope{
+        ID:        msg.EventID,
+        Type:      msg.EventType,
+        CreatedAt: msg.CreatedAt,
+        Data:      msg.Payload,
+    })
+    if err != nil {
+        return 0, err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        return 0, err
+    }
+
+    now := time.Now()
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(EventHeader, msg.EventType)
+    req.Header.Set(DeliveryHeader, msg.DeliveryID.String())
+    req.Header.Set(TimestampHeader, fmt.Sprintf("%d", now.Unix()))
+    req.Header.Set(SignatureHeader, auth.SignWebhook(secret, now, body))
+
+    resp, err := s.Client.Do(req)
+    if err != nil {
+        return 0, err
+    }
+    defer resp.Body.Close()
+    io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+    }
+    return resp.StatusCode, nil
+}
@@ -0,0 +1,137 @@
+package outbound
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/netip"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+)
+
+// testSender may connect to the loopback test servers NewSender refuses.
+func testSender() *Sender {
+    return newSender(time.Second, func(netip.Addr) bool { return true })
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+    secret := "endpoint-secret"
+    verifier := auth.NewWebhookVerifier(SignatureHeader, TimestampHeader, time.Minute, secret)
+
+    msg := Message{
+        DeliveryID: uuid.New(),
+        EventID:    uuid.New(),
+        EventType:  "chirp.created",
+        CreatedAt:  time.Now().UTC(),
+        Payload:    json.RawMessage(`{"body":"hello"}`),
+    }
+
+    var received envelope
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, err := io.ReadAll(r.Body)
+        assert.NoError(t, err)
+        assert.NoError(t, verifier.Verify(r.Header, body))
+        assert.Equal(t, "chirp.created", r.Header.Get(EventHeader))
+        assert.Equal(t, msg.DeliveryID.String(), r.Header.Get(DeliveryHeader))
+        assert.NoError(t, json.Unmarshal(body, &received))
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    defer server.Close()
+
+    status, err := testSender().Send(context.Background(), server.URL, secret, msg)
+    assert.NoError(t, err)
+    assert.Equal(t, http.StatusNoContent, status)
+    assert.Equal(t, msg.EventID, received.ID)
+    assert.Equal(t, "chirp.created", received.Type)
+    assert.JSONEq(t, `{"body":"hello"}`, string(received.Data))
+}
+
+func TestSendReportsFailures(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+
+    sender := testSender()
+    msg := Message{EventType: "chirp.deleted", Payload: json.RawMessage(`{}`)}
+
+    status, err := sender.Send(context.Background(), server.URL, "secret", msg)
+    assert.Error(t, err)
+    assert.Equal(t, http.StatusServiceUnavailable, status)
+
+    // Unreachable endpoint
+    server.Close()
+    status, err = sender.Send(context.Background(), server.URL, "secret", msg)
+    assert.Error(t, err)
+    assert.Equal(t, 0, status)
+}
+
+func TestSendRefusesUnroutableAddresses(t *testing.T) {
+    reached := false
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reached = true
+    }))
+    defer server.Close()
+
+    msg := Message{EventType: "chirp.deleted", Payload: json.RawMessage(`{}`)}
+    status, err := NewSender(time.Second).Send(context.Background(), server.URL, "secret", msg)
+    assert.ErrorIs(t, err, ErrUnroutable)
+    assert.Equal(t, 0, status)
+    assert.False(t, reached)
+}
+
+func TestRoutable(t *testing.T) {
+    for addr, want := range map[string]bool{
+        "93.184.216.34":      true,
+        "2606:4700::1111":    true,
+        "127.0.0.1":          false,
+        "10.1.2.3":           false,
+        "172.16.0.1":         false,
+        "192.168.1.1":        false,
+        "169.254.169.254":    false,
+        "100.64.0.1":         false,
+        "0.0.0.0":            false,
+        "::1":                false,
+        "fd00:ec2::254":      false,
+        "fe80::1":            false,
+        "::ffff:127.0.0.1":   false,
+        "64:ff9b::a9fe:a9fe": false,
+    } {
+        assert.Equal(t, want, Routable(netip.MustParseAddr(addr)), addr)
+    }
+}
+
+func TestCheckURL(t *testing.T) {
+    ctx := context.Background()
+
+    parsed, err := CheckURL(ctx, "https://93.184.216.34/hook", true)
+    assert.NoError(t, err)
+    assert.Equal(t, "93.184.216.34", parsed.Hostname())
+
+    _, err = CheckURL(ctx, "http://93.184.216.34/hook", true)
+    assert.ErrorContains(t, err, "https")
+    _, err = CheckURL(ctx, "http://93.184.216.34/hook", false)
+    assert.NoError(t, err)
+
+    for _, url := range []string{"http://127.0.0.1:8080/", "https://169.254.169.254/latest/meta-data", "https://[::1]/", "https://localhost/"} {
+        _, err = CheckURL(ctx, url, false)
+        assert.ErrorIs(t, err, ErrUnroutable, url)
+    }
+
+    _, err = CheckURL(ctx, "ftp://example.com/", false)
+    assert.Error(t, err)
+    _, err = CheckURL(ctx, "/relative", false)
+    assert.Error(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+    assert.Equal(t, 30*time.Second, Backoff(1))
+    assert.Equal(t, time.Minute, Backoff(2))
+    assert.Equal(t, 4*time.Minute, Backoff(4))
+    assert.Equal(t, 6*time.Hour, Backoff(20))
+}
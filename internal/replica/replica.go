@@ -0,0 +1,244 @@
+// Package replica routes read-only queries to Postgres read replicas.
+package replica
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/tracing"
+    "github.com/google/uuid"
+)
+
+// pingTimeout bounds the ping used to tell a replica that is down from a
+// query that failed on its own.
+const pingTimeout = 2 * time.Second
+
+// Replica is one read replica and whether it is currently taking reads.
+type Replica struct {
+    Name string
+    db   *sql.DB
+    down atomic.Bool
+}
+
+// Healthy reports whether the replica is taking reads.
+func (r *Replica) Healthy() bool {
+    return !r.down.Load()
+}
+
+// Router is a DBTX that sends the queries named in reads to a healthy
+// replica and everything else to the primary. Queries are told apart by the
+// name sqlc puts in each one.
+//
+// A user who has just written reads from the primary for the stickiness
+// window after the write, so they see their own changes before they reach
+// the replicas. The user is the one SetSessionUser recorded for the
+// request, and the record of recent writers is kept in the process, so it
+// only holds for requests served by the same instance.
+//
+// A replica whose query fails and which then fails a ping is taken out of
+// rotation and the query is run on the primary. Check brings it back once
+// it answers again.
+type Router struct {
+    primary    tracing.DBTX
+    replicas   []*Replica
+    reads      map[string]bool
+    stickiness time.Duration
+    next       atomic.Uint64
+
+    mu      sync.Mutex
+    writers map[uuid.UUID]time.Time
+    now     func() time.Time
+}
+
+var _ tracing.DBTX = (*Router)(nil)
+
+// NewRouter returns a Router over the primary and the replicas, which are
+// named after their position in the list, replica-1 first.
+func NewRouter(primary *sql.DB, replicas []*sql.DB, reads []string, stickiness time.Duration) *Router {
+    r := &Router{
+        primary:    primary,
+        reads:      map[string]bool{},
+        stickiness: stickiness,
+        writers:    map[uuid.UUID]time.Time{},
+        now:        time.Now,
+    }
+    for i, db := range replicas {
+        r.replicas = append(r.replicas, &Replica{Name: "replica-" + strconv.Itoa(i+1), db: db})
+    }
+    for _, name := range reads {
+        r.reads[name] = true
+    }
+    return r
+}
+
+// Replicas lists the replicas, for health checks and metrics.
+func (r *Router) Replicas() []*Replica {
+    return r.replicas
+}
+
+// Close closes the replica connections. The primary belongs to the caller.
+func (r *Router) Close() error {
+    var errs []error
+    for _, replica := range r.replicas {
+        errs = append(errs, replica.db.Close())
+    }
+    return errors.Join(errs...)
+}
+
+// Ping checks the replica directly, whether or not it is in rotation.
+func (r *Replica) Ping(ctx context.Context) error {
+    return r.db.PingContext(ctx)
+}
+
+func (r *Router) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    r.Wrote(ctx)
+    return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *Router) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+    return r.primary.PrepareContext(ctx, query)
+}
+
+func (r *Router) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    replica := r.pick(ctx, query)
+    if replica == nil {
+        return r.primary.QueryContext(ctx, query, args...)
+    }
+    rows, err := replica.db.QueryContext(ctx, query, args...)
+    if err != nil && r.failed(ctx, replica) {
+        return r.primary.QueryContext(ctx, query, args...)
+    }
+    return rows, err
+}
+
+func (r *Router) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    replica := r.pick(ctx, query)
+    if replica == nil {
+        return r.primary.QueryRowContext(ctx, query, args...)
+    }
+    row := replica.db.QueryRowContext(ctx, query, args...)
+    if err := row.Err(); err != nil && r.failed(ctx, replica) {
+        return r.primary.QueryRowContext(ctx, query, args...)
+    }
+    return row
+}
+
+// pick returns the replica to run query on, or nil for the primary.
+func (r *Router) pick(ctx context.Context, query string) *Replica {
+    if !r.reads[tracing.QueryName(query)] {
+        // sqlc runs INSERT ... RETURNING through QueryRowContext too.
+        if isWrite(query) {
+            r.Wrote(ctx)
+        }
+        return nil
+    }
+    if r.sticky(ctx) {
+        return nil
+    }
+
+    // Round-robin over the replicas, skipping those that are down.
+    start := r.next.Add(1)
+    for i := range r.replicas {
+        replica := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+        if replica.Healthy() {
+            return replica
+        }
+    }
+    return nil
+}
+
+// failed decides whether a query error on replica means the replica is
+// down, in which case it is taken out of rotation and the caller retries
+// on the primary. Errors from a replica that still answers pings, such as
+// a cancelled context, are returned as they are.
+func (r *Router) failed(ctx context.Context, replica *Replica) bool {
+    if ctx.Err() != nil {
+        return false
+    }
+    pingCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), pingTimeout)
+    defer cancel()
+    if replica.db.PingContext(pingCtx) == nil {
+        return false
+    }
+    replica.down.Store(true)
+    return true
+}
+
+// Check pings every replica, putting those that answer back into rotation
+// and taking out those that don't. It returns how many are healthy.
+func (r *Router) Check(ctx context.Context) int {
+    healthy := 0
+    for _, replica := range r.replicas {
+        pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+        err := replica.db.PingContext(pingCtx)
+        cancel()
+        replica.down.Store(err != nil)
+        if err == nil {
+            healthy++
+        }
+    }
+    return healthy
+}
+
+// Wrote records that the user of the request in ctx has written to the
+// primary, so their reads stay on the primary for the stickiness window.
+// Writes made outside the Router, such as in a transaction, must call it
+// themselves.
+func (r *Router) Wrote(ctx context.Context) {
+    userID := SessionUser(ctx)
+    if userID == uuid.Nil || r.stickiness <= 0 {
+        return
+    }
+    r.mu.Lock()
+    r.writers[userID] = r.now()
+    r.mu.Unlock()
+}
+
+func (r *Router) sticky(ctx context.Context) bool {
+    userID := SessionUser(ctx)
+    if userID == uuid.Nil || r.stickiness <= 0 {
+        return false
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    wroteAt, ok := r.writers[userID]
+    if !ok {
+        return false
+    }
+    if r.now().Sub(wroteAt) >= r.stickiness {
+        delete(r.writers, userID)
+        return false
+    }
+    return true
+}
+
+// Forget drops writers whose stickiness window has passed. Entries are also
+// dropped when their user next reads, so this only bounds the memory held
+// for users who wrote and went away.
+func (r *Router) Forget() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    now := r.now()
+    for userID, wroteAt := range r.writers {
+        if now.Sub(wroteAt) >= r.stickiness {
+            delete(r.writers, userID)
+        }
+    }
+}
+
+// isWrite reports whether query may change data: anything but a plain
+// SELECT after the sqlc name comment.
+func isWrite(query string) bool {
+    query = strings.TrimSpace(query)
+    if strings.HasPrefix(query, "--") {
+        _, query, _ = strings.Cut(query, "\n")
+    }
+    words := strings.Fields(query)
+    return len(words) == 0 || !strings.EqualFold(words[0], "SELECT")
+}
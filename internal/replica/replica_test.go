@@ -0,0 +1,142 @@
+package replica
+
+import (
+    "context"
+    "database/sql"
+    "testing"
+    "time"
+
+    "github.com/google/uuid"
+    _ "github.com/mattn/go-sqlite3"
+    "github.com/stretchr/testify/assert"
+)
+
+const (
+    readQuery  = "-- name: GetAllChirps :many\nSELECT name FROM whoami"
+    writeQuery = "-- name: CreateChirp :one\nINSERT INTO writes (n) VALUES (1) RETURNING n"
+    otherQuery = "-- name: GetUserByID :one\nSELECT name FROM whoami"
+)
+
+// openNamed opens an in-memory database that answers readQuery with name.
+func openNamed(t *testing.T, name string) *sql.DB {
+    t.Helper()
+    db, err := sql.Open("sqlite3", ":memory:")
+    assert.NoError(t, err)
+    db.SetMaxOpenConns(1)
+    t.Cleanup(func() { db.Close() })
+
+    _, err = db.Exec("CREATE TABLE whoami (name TEXT); CREATE TABLE writes (n INTEGER)")
+    assert.NoError(t, err)
+    _, err = db.Exec("INSERT INTO whoami VALUES (?)", name)
+    assert.NoError(t, err)
+    return db
+}
+
+func servedBy(t *testing.T, r *Router, ctx context.Context, query string) string {
+    t.Helper()
+    var name string
+    assert.NoError(t, r.QueryRowContext(ctx, query).Scan(&name))
+    return name
+}
+
+func withUser(userID uuid.UUID) context.Context {
+    return context.WithValue(context.Background(), sessionKey{}, &session{userID: userID})
+}
+
+func TestRouterSplitsReadsAndWrites(t *testing.T) {
+    primary := openNamed(t, "primary")
+    r := NewRouter(primary, []*sql.DB{openNamed(t, "one"), openNamed(t, "two")}, []string{"GetAllChirps"}, time.Minute)
+    ctx := context.Background()
+
+    seen := map[string]bool{}
+    for range 4 {
+        seen[servedBy(t, r, ctx, readQuery)] = true
+    }
+    assert.Equal(t, map[string]bool{"one": true, "two": true}, seen)
+
+    // Queries not listed as reads stay on the primary.
+    assert.Equal(t, "primary", servedBy(t, r, ctx, otherQuery))
+
+    var n int
+    assert.NoError(t, r.QueryRowContext(ctx, writeQuery).Scan(&n))
+    var count int
+    assert.NoError(t, primary.QueryRow("SELECT COUNT(*) FROM writes").Scan(&count))
+    assert.Equal(t, 1, count)
+}
+
+func TestRouterReadYourWrites(t *testing.T) {
+    r := NewRouter(openNamed(t, "primary"), []*sql.DB{openNamed(t, "replica")}, []string{"GetAllChirps"}, time.Minute)
+    now := time.Now()
+    r.now = func() time.Time { return now }
+
+    writer := withUser(uuid.New())
+    reader := withUser(uuid.New())
+
+    var n int
+    assert.NoError(t, r.QueryRowContext(writer, writeQuery).Scan(&n))
+    assert.Equal(t, "primary", servedBy(t, r, writer, readQuery))
+    assert.Equal(t, "replica", servedBy(t, r, reader, readQuery))
+
+    // Reading doesn't make a user sticky.
+    assert.Equal(t, "primary", servedBy(t, r, reader, otherQuery))
+    assert.Equal(t, "replica", servedBy(t, r, reader, readQuery))
+
+    // Anonymous writes can't be followed.
+    assert.NoError(t, r.QueryRowContext(context.Background(), writeQuery).Scan(&n))
+    assert.Equal(t, "replica", servedBy(t, r, context.Background(), readQuery))
+
+    now = now.Add(time.Minute)
+    assert.Equal(t, "replica", servedBy(t, r, writer, readQuery))
+
+    r.Wrote(writer)
+    assert.Equal(t, "primary", servedBy(t, r, writer, readQuery))
+    now = now.Add(time.Minute)
+    r.Forget()
+    assert.Empty(t, r.writers)
+}
+
+func TestRouterFallsBackToPrimary(t *testing.T) {
+    down := openNamed(t, "down")
+    r := NewRouter(openNamed(t, "primary"), []*sql.DB{down}, []string{"GetAllChirps"}, time.Minute)
+    ctx := context.Background()
+
+    assert.Equal(t, "down", servedBy(t, r, ctx, readQuery))
+    down.Close()
+
+    assert.Equal(t, "primary", servedBy(t, r, ctx, readQuery))
+    assert.False(t, r.Replicas()[0].Healthy())
+
+    rows, err := r.QueryContext(ctx, readQuery)
+    assert.NoError(t, err)
+    rows.Close()
+
+    assert.Equal(t, 0, r.Check(ctx))
+}
+
+func TestRouterKeepsReplicaOnQueryError(t *testing.T) {
+    r := NewRouter(openNamed(t, "primary"), []*sql.DB{openNamed(t, "replica")}, []string{"GetAllChirps"}, time.Minute)
+
+    // The replica still answers pings, so a broken query is its own fault.
+    err := r.QueryRowContext(context.Background(), "-- name: GetAllChirps :many\nSELECT missing FROM whoami").Scan(new(string))
+    assert.Error(t, err)
+    assert.True(t, r.Replicas()[0].Healthy())
+    assert.Equal(t, 1, r.Check(context.Background()))
+}
+
+func TestIsWrite(t *testing.T) {
+    assert.False(t, isWrite("-- name: GetAllChirps :many\nSELECT * FROM chirps"))
+    assert.False(t, isWrite("select 1"))
+    assert.True(t, isWrite("-- name: CreateChirp :one\nINSERT INTO chirps DEFAULT VALUES RETURNING *"))
+    assert.True(t, isWrite("-- name: SoftDeleteChirp :exec\nUPDATE chirps SET deleted_at = NOW()"))
+    assert.True(t, isWrite("WITH gone AS (DELETE FROM chirps RETURNING id) SELECT COUNT(*) FROM gone"))
+}
+
+func TestSession(t *testing.T) {
+    assert.Equal(t, uuid.Nil, SessionUser(context.Background()))
+    SetSessionUser(context.Background(), uuid.New())
+
+    userID := uuid.New()
+    ctx := context.WithValue(context.Background(), sessionKey{}, &session{})
+    SetSessionUser(ctx, userID)
+    assert.Equal(t, userID, SessionUser(ctx))
+}
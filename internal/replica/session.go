@@ -0,0 +1,43 @@
+package replica
+
+import (
+    "context"
+    "net/http"
+
+    "github.com/google/uuid"
+)
+
+type sessionKey struct{}
+
+// session holds the user a request is made by. It is put in the context
+// before the user is known and filled in once the request is authenticated,
+// so the Router can tell whose reads to keep on the primary.
+type session struct {
+    userID uuid.UUID
+}
+
+// Middleware gives each request a session for SetSessionUser to fill in. It
+// replaces the request, so it belongs outside any middleware that reads what
+// the ServeMux sets on the request it is given, such as Pattern.
+func Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := context.WithValue(r.Context(), sessionKey{}, &session{})
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+// SetSessionUser records the authenticated user of the request in ctx. It
+// does nothing outside Middleware.
+func SetSessionUser(ctx context.Context, userID uuid.UUID) {
+    if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+        s.userID = userID
+    }
+}
+
+// SessionUser is the user recorded by SetSessionUser, or uuid.Nil.
+func SessionUser(ctx context.Context) uuid.UUID {
+    if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+        return s.userID
+    }
+    return uuid.Nil
+}
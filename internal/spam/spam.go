@@ -0,0 +1,177 @@
+package spam
+
+import (
+    "errors"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+)
+
+type Action string
+
+const (
+    ActionReject Action = "reject"
+    ActionQueue  Action = "queue"
+)
+
+const (
+    RulePostingRate = "posting_rate"
+    RuleNewAccount  = "new_account"
+    RuleDuplicate   = "duplicate"
+    RuleLinks       = "links"
+)
+
+// Policy holds the tunable limits for new chirps. A zero limit disables the
+// corresponding check.
+type Policy struct {
+    WindowSeconds            int     `json:"window_seconds"`
+    PostsPerWindow           int     `json:"posts_per_window"`
+    RedPostsPerWindow        int     `json:"red_posts_per_window"`
+    NewAccountAgeSeconds     int     `json:"new_account_age_seconds"`
+    NewAccountPostsPerWindow int     `json:"new_account_posts_per_window"`
+    RateAction               Action  `json:"rate_action"`
+    DuplicateWindowSeconds   int     `json:"duplicate_window_seconds"`
+    DuplicateAction          Action  `json:"duplicate_action"`
+    MaxLinks                 int     `json:"max_links"`
+    MaxLinkRatio             float64 `json:"max_link_ratio"`
+    LinkAction               Action  `json:"link_action"`
+}
+
+func DefaultPolicy() Policy {
+    return Policy{
+        WindowSeconds:            60,
+        PostsPerWindow:           5,
+        RedPostsPerWindow:        20,
+        NewAccountAgeSeconds:     24 * 60 * 60,
+        NewAccountPostsPerWindow: 2,
+        RateAction:               ActionReject,
+        DuplicateWindowSeconds:   10 * 60,
+        DuplicateAction:          ActionReject,
+        MaxLinks:                 3,
+        MaxLinkRatio:             0.5,
+        LinkAction:               ActionQueue,
+    }
+}
+
+func (p Policy) Validate() error {
+    var problems []string
+    if p.WindowSeconds <= 0 {
+        problems = append(problems, "window_seconds must be positive")
+    }
+    if p.PostsPerWindow < 0 || p.RedPostsPerWindow < 0 || p.NewAccountPostsPerWindow < 0 {
+        problems = append(problems, "post limits cannot be negative")
+    }
+    if p.NewAccountAgeSeconds < 0 || p.DuplicateWindowSeconds < 0 {
+        problems = append(problems, "durations cannot be negative")
+    }
+    if p.MaxLinks < 0 || p.MaxLinkRatio < 0 || p.MaxLinkRatio > 1 {
+        problems = append(problems, "max_links cannot be negative and max_link_ratio must be between 0 and 1")
+    }
+    for _, action := range []Action{p.RateAction, p.DuplicateAction, p.LinkAction} {
+        if action != ActionReject && action != ActionQueue {
+            problems = append(problems, "actions must be reject or queue")
+            break
+        }
+    }
+    if len(problems) > 0 {
+        return errors.New(strings.Join(problems, "; "))
+    }
+    return nil
+}
+
+func (p Policy) Window() time.Duration {
+    return time.Duration(p.WindowSeconds) * time.Second
+}
+
+func (p Policy) DuplicateWindow() time.Duration {
+    return time.Duration(p.DuplicateWindowSeconds) * time.Second
+}
+
+// Post describes a chirp about to be created and its author's recent activity.
+type Post struct {
+    Body        string
+    RecentPosts int64
+    Duplicates  int64
+    AccountAge  time.Duration
+    IsChirpyRed bool
+}
+
+type Violation struct {
+    Rule   string `json:"rule"`
+    Action Action `json:"action"`
+}
+
+// Evaluate returns every rule the post breaks under the policy.
+func (p Policy) Evaluate(post Post) []Violation {
+    var violations []Violation
+
+    limit := p.PostsPerWindow
+    rule := RulePostingRate
+    if post.IsChirpyRed {
+        limit = p.RedPostsPerWindow
+    }
+    if p.NewAccountAgeSeconds > 0 && post.AccountAge < time.Duration(p.NewAccountAgeSeconds)*time.Second {
+        if p.NewAccountPostsPerWindow > 0 && (limit == 0 || p.NewAccountPostsPerWindow < limit) {
+            limit = p.NewAccountPostsPerWindow
+            rule = RuleNewAccount
+        }
+    }
+    if limit > 0 && post.RecentPosts >= int64(limit) {
+        violations = append(violations, Violation{Rule: rule, Action: p.RateAction})
+    }
+
+    if p.DuplicateWindowSeconds > 0 && post.Duplicates > 0 {
+        violations = append(violations, Violation{Rule: RuleDuplicate, Action: p.DuplicateAction})
+    }
+
+    links, ratio := LinkDensity(post.Body)
+    if (p.MaxLinks > 0 && links > p.MaxLinks) || (p.MaxLinkRatio > 0 && links > 0 && ratio > p.MaxLinkRatio) {
+        violations = append(violations, Violation{Rule: RuleLinks, Action: p.LinkAction})
+    }
+
+    return violations
+}
+
+var linkPattern = regexp.MustCompile(`(?i)^(https?://|www\.)\S+|^[a-z0-9-]+(\.[a-z0-9-]+)*\.(com|net|org|io|co|ly|xyz|info|biz|me|app)(/\S*)?$`)
+
+// LinkDensity counts the links in body and the share of its words that are
+// links.
+func LinkDensity(body string) (int, float64) {
+    words := strings.Fields(body)
+    if len(words) == 0 {
+        return 0, 0
+    }
+
+    links := 0
+    for _, word := range words {
+        if linkPattern.MatchString(strings.Trim(word, `.,;:!?()[]"'`)) {
+            links++
+        }
+    }
+
+    return links, float64(links) / float64(len(words))
+}
+
+// Detector holds the active policy and lets it be replaced while the server
+// is running.
+type Detector struct {
+    mu     sync.RWMutex
+    policy Policy
+}
+
+func NewDetector(policy Policy) *Detector {
+    return &Detector{policy: policy}
+}
+
+func (d *Detector) Policy() Policy {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+    return d.policy
+}
+
+func (d *Detector) Load(policy Policy) {
+    d.mu.Lock()
+    d.policy = policy
+    d.mu.Unlock()
+}
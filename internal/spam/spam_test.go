@@ -0,0 +1,62 @@
+package spam
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPolicyIsValid(t *testing.T) {
+    assert.NoError(t, DefaultPolicy().Validate())
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+    policy := DefaultPolicy()
+    policy.WindowSeconds = 0
+    policy.LinkAction = "delete"
+
+    err := policy.Validate()
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "window_seconds")
+    assert.Contains(t, err.Error(), "actions")
+}
+
+func TestEvaluatePostingRate(t *testing.T) {
+    policy := DefaultPolicy()
+    established := 30 * 24 * time.Hour
+
+    assert.Empty(t, policy.Evaluate(Post{Body: "hi", RecentPosts: 4, AccountAge: established}))
+
+    violations := policy.Evaluate(Post{Body: "hi", RecentPosts: 5, AccountAge: established})
+    assert.Equal(t, []Violation{{Rule: RulePostingRate, Action: ActionReject}}, violations)
+
+    // Chirpy Red members get a higher quota
+    assert.Empty(t, policy.Evaluate(Post{Body: "hi", RecentPosts: 5, AccountAge: established, IsChirpyRed: true}))
+}
+
+func TestEvaluateNewAccountThrottle(t *testing.T) {
+    policy := DefaultPolicy()
+
+    violations := policy.Evaluate(Post{Body: "hi", RecentPosts: 2, AccountAge: time.Hour, IsChirpyRed: true})
+    assert.Equal(t, []Violation{{Rule: RuleNewAccount, Action: ActionReject}}, violations)
+}
+
+func TestEvaluateDuplicates(t *testing.T) {
+    violations := DefaultPolicy().Evaluate(Post{Body: "same again", Duplicates: 1, AccountAge: 30 * 24 * time.Hour})
+    assert.Equal(t, []Violation{{Rule: RuleDuplicate, Action: ActionReject}}, violations)
+}
+
+func TestLinkDensity(t *testing.T) {
+    links, ratio := LinkDensity("check https://example.com and www.foo.org, or bar.io!")
+    assert.Equal(t, 3, links)
+    assert.InDelta(t, 0.5, ratio, 0.001)
+
+    links, _ = LinkDensity("no links here, just words.")
+    assert.Equal(t, 0, links)
+}
+
+func TestEvaluateLinks(t *testing.T) {
+    violations := DefaultPolicy().Evaluate(Post{Body: "https://a.com https://b.com", AccountAge: 30 * 24 * time.Hour})
+    assert.Equal(t, []Violation{{Rule: RuleLinks, Action: ActionQueue}}, violations)
+}
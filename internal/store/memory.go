@@ -0,0 +1,570 @@
+package store
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/google/uuid"
+)
+
+// Memory is a Store that lives in the process and is lost on exit.
+//
+// It applies the visibility rules of the Postgres queries to the follows,
+// follow requests, mentions, blocks and mutes it records.
+type Memory struct {
+    mu       sync.RWMutex
+    users    map[uuid.UUID]database.User
+    tokens   map[string]database.RefreshToken
+    chirps   map[uuid.UUID]database.Chirp
+    follows  map[edge]bool
+    requests map[edge]bool
+    mentions map[edge]bool
+    blocks   map[edge]bool
+    mutes    map[edge]bool
+}
+
+// edge is a directed relationship between users, such as a follower and the
+// user they follow, or between a chirp and a user it mentions.
+type edge struct {
+    from, to uuid.UUID
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+    return &Memory{
+        users:    map[uuid.UUID]database.User{},
+        tokens:   map[string]database.RefreshToken{},
+        chirps:   map[uuid.UUID]database.Chirp{},
+        follows:  map[edge]bool{},
+        requests: map[edge]bool{},
+        mentions: map[edge]bool{},
+        blocks:   map[edge]bool{},
+        mutes:    map[edge]bool{},
+    }
+}
+
+func (m *Memory) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if m.emailTaken(arg.Email, uuid.Nil) {
+        return database.User{}, ErrEmailTaken
+    }
+
+    now := time.Now().UTC()
+    user := database.User{
+        ID:             uuid.New(),
+        CreatedAt:      now,
+        UpdatedAt:      now,
+        Email:          arg.Email,
+        HashedPassword: arg.HashedPassword,
+        Role:           auth.RoleUser,
+    }
+    m.users[user.ID] = user
+    return user, nil
+}
+
+func (m *Memory) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    user, ok := m.users[id]
+    if !ok {
+        return database.User{}, sql.ErrNoRows
+    }
+    return user, nil
+}
+
+func (m *Memory) GetUserByEmail(ctx context.Context, email string) (database.User, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    for _, user := range m.users {
+        if user.Email == email {
+            return user, nil
+        }
+    }
+    return database.User{}, sql.ErrNoRows
+}
+
+func (m *Memory) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.UpdateUserRow, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    user, ok := m.users[arg.ID]
+    if !ok {
+        return database.UpdateUserRow{}, sql.ErrNoRows
+    }
+    if m.emailTaken(arg.Email, arg.ID) {
+        return database.UpdateUserRow{}, ErrEmailTaken
+    }
+
+    user.Email = arg.Email
+    user.HashedPassword = arg.HashedPassword
+    user.UpdatedAt = arg.UpdatedAt
+    m.users[user.ID] = user
+
+    return database.UpdateUserRow{
+        ID:          user.ID,
+        CreatedAt:   user.CreatedAt,
+        UpdatedAt:   user.UpdatedAt,
+        Email:       user.Email,
+        IsChirpyRed: user.IsChirpyRed,
+    }, nil
+}
+
+func (m *Memory) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) (database.SetUserRoleRow, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    user, ok := m.users[arg.ID]
+    if !ok {
+        return database.SetUserRoleRow{}, sql.ErrNoRows
+    }
+    user.Role = arg.Role
+    user.UpdatedAt = time.Now().UTC()
+    m.users[user.ID] = user
+
+    return database.SetUserRoleRow{ID: user.ID, Email: user.Email, Role: user.Role}, nil
+}
+
+func (m *Memory) SetUserPrivacy(ctx context.Context, arg database.SetUserPrivacyParams) (database.SetUserPrivacyRow, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    user, ok := m.users[arg.ID]
+    if !ok {
+        return database.SetUserPrivacyRow{}, sql.ErrNoRows
+    }
+    user.IsPrivate = arg.IsPrivate
+    user.UpdatedAt = time.Now().UTC()
+    m.users[user.ID] = user
+
+    return database.SetUserPrivacyRow{
+        ID:          user.ID,
+        CreatedAt:   user.CreatedAt,
+        UpdatedAt:   user.UpdatedAt,
+        Email:       user.Email,
+        IsChirpyRed: user.IsChirpyRed,
+        IsPrivate:   user.IsPrivate,
+    }, nil
+}
+
+func (m *Memory) SuspendUser(ctx context.Context, arg database.SuspendUserParams) (int64, error) {
+    return m.sanction(arg.ID, func(user *database.User, now time.Time) {
+        user.SuspendedAt = sql.NullTime{Time: now, Valid: true}
+        user.SuspendedUntil = arg.SuspendedUntil
+    }), nil
+}
+
+func (m *Memory) ShadowBanUser(ctx context.Context, arg database.ShadowBanUserParams) (int64, error) {
+    return m.sanction(arg.ID, func(user *database.User, now time.Time) {
+        user.ShadowBannedAt = sql.NullTime{Time: now, Valid: true}
+        user.ShadowBannedUntil = arg.ShadowBannedUntil
+    }), nil
+}
+
+// sanction applies apply to the user with the given id and returns how many
+// users it changed, as the :execrows queries do.
+func (m *Memory) sanction(id uuid.UUID, apply func(user *database.User, now time.Time)) int64 {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    user, ok := m.users[id]
+    if !ok {
+        return 0
+    }
+    now := time.Now().UTC()
+    apply(&user, now)
+    user.UpdatedAt = now
+    m.users[id] = user
+    return 1
+}
+
+// DeleteAllUsers removes every user along with everything they own.
+func (m *Memory) DeleteAllUsers(ctx context.Context) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    clear(m.users)
+    clear(m.tokens)
+    clear(m.chirps)
+    clear(m.follows)
+    clear(m.requests)
+    clear(m.mentions)
+    clear(m.blocks)
+    clear(m.mutes)
+    return nil
+}
+
+func (m *Memory) emailTaken(email string, except uuid.UUID) bool {
+    for _, user := range m.users {
+        if user.Email == email && user.ID != except {
+            return true
+        }
+    }
+    return false
+}
+
+func (m *Memory) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[arg.UserID]; !ok {
+        return fmt.Errorf("refresh token for unknown user %s", arg.UserID)
+    }
+    if _, ok := m.tokens[arg.Token]; ok {
+        return fmt.Errorf("duplicate refresh token")
+    }
+
+    m.tokens[arg.Token] = database.RefreshToken{
+        Token:     arg.Token,
+        CreatedAt: arg.CreatedAt,
+        UpdatedAt: arg.UpdatedAt,
+        UserID:    arg.UserID,
+        ExpiresAt: arg.ExpiresAt,
+        RevokedAt: arg.RevokedAt,
+    }
+    return nil
+}
+
+func (m *Memory) GetRefreshToken(ctx context.Context, token string) (database.RefreshToken, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    refreshToken, ok := m.tokens[token]
+    if !ok {
+        return database.RefreshToken{}, sql.ErrNoRows
+    }
+    return refreshToken, nil
+}
+
+func (m *Memory) RevokeRefreshToken(ctx context.Context, arg database.RevokeRefreshTokenParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if refreshToken, ok := m.tokens[arg.Token]; ok {
+        refreshToken.RevokedAt = arg.RevokedAt
+        refreshToken.UpdatedAt = arg.UpdatedAt
+        m.tokens[arg.Token] = refreshToken
+    }
+    return nil
+}
+
+func (m *Memory) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    now := time.Now().UTC()
+    for token, refreshToken := range m.tokens {
+        if refreshToken.UserID == userID && !refreshToken.RevokedAt.Valid {
+            refreshToken.RevokedAt = sql.NullTime{Time: now, Valid: true}
+            refreshToken.UpdatedAt = now
+            m.tokens[token] = refreshToken
+        }
+    }
+    return nil
+}
+
+func (m *Memory) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[arg.UserID]; !ok {
+        return database.Chirp{}, fmt.Errorf("chirp by unknown user %s", arg.UserID)
+    }
+    if _, ok := m.chirps[arg.ID]; ok {
+        return database.Chirp{}, fmt.Errorf("duplicate chirp %s", arg.ID)
+    }
+
+    chirp := database.Chirp{
+        ID:         arg.ID,
+        CreatedAt:  arg.CreatedAt,
+        UpdatedAt:  arg.UpdatedAt,
+        Body:       arg.Body,
+        UserID:     arg.UserID,
+        Visibility: arg.Visibility,
+    }
+    m.chirps[chirp.ID] = chirp
+    return chirp, nil
+}
+
+func (m *Memory) GetAllChirps(ctx context.Context, arg database.GetAllChirpsParams) ([]database.Chirp, error) {
+    return m.listChirps(func(chirp database.Chirp) bool {
+        return m.listed(chirp, arg.ViewerID, arg.Now)
+    }), nil
+}
+
+func (m *Memory) GetChirpsByAuthor(ctx context.Context, arg database.GetChirpsByAuthorParams) ([]database.Chirp, error) {
+    return m.listChirps(func(chirp database.Chirp) bool {
+        return chirp.UserID == arg.UserID && m.listed(chirp, arg.ViewerID, arg.Now)
+    }), nil
+}
+
+func (m *Memory) GetChirpByID(ctx context.Context, arg database.GetChirpByIDParams) (database.Chirp, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    chirp, ok := m.chirps[arg.ID]
+    if !ok || !m.visible(chirp, arg.ViewerID, arg.Now, true) {
+        return database.Chirp{}, sql.ErrNoRows
+    }
+    return chirp, nil
+}
+
+func (m *Memory) SoftDeleteChirp(ctx context.Context, arg database.SoftDeleteChirpParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    chirp, ok := m.chirps[arg.ID]
+    if !ok || chirp.DeletedAt.Valid {
+        return nil
+    }
+    chirp.DeletedAt = sql.NullTime{Time: arg.Now, Valid: true}
+    chirp.UpdatedAt = arg.Now
+    m.chirps[arg.ID] = chirp
+    return nil
+}
+
+func (m *Memory) GetDeletedChirpsByAuthor(ctx context.Context, arg database.GetDeletedChirpsByAuthorParams) ([]database.Chirp, error) {
+    chirps := m.listChirps(func(chirp database.Chirp) bool {
+        return chirp.UserID == arg.UserID && chirp.DeletedAt.Valid && !chirp.DeletedAt.Time.Before(arg.DeletedSince)
+    })
+    sort.SliceStable(chirps, func(i, j int) bool {
+        return chirps[i].DeletedAt.Time.After(chirps[j].DeletedAt.Time)
+    })
+    return chirps, nil
+}
+
+func (m *Memory) GetDeletedChirpByID(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    chirp, ok := m.chirps[id]
+    if !ok || !chirp.DeletedAt.Valid {
+        return database.Chirp{}, sql.ErrNoRows
+    }
+    return chirp, nil
+}
+
+func (m *Memory) RestoreChirp(ctx context.Context, arg database.RestoreChirpParams) (database.Chirp, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    chirp, ok := m.chirps[arg.ID]
+    if !ok || !chirp.DeletedAt.Valid {
+        return database.Chirp{}, sql.ErrNoRows
+    }
+    chirp.DeletedAt = sql.NullTime{}
+    chirp.UpdatedAt = arg.Now
+    m.chirps[arg.ID] = chirp
+    return chirp, nil
+}
+
+func (m *Memory) PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var n int64
+    for id, chirp := range m.chirps {
+        if chirp.DeletedAt.Valid && chirp.DeletedAt.Time.Before(deletedBefore) {
+            delete(m.chirps, id)
+            n++
+        }
+    }
+    for mention := range m.mentions {
+        if _, ok := m.chirps[mention.from]; !ok {
+            delete(m.mentions, mention)
+        }
+    }
+    return n, nil
+}
+
+// CreateChirpMentions records the mentions of users that exist and have no
+// block between them and the author, and ignores the rest, as the query does.
+func (m *Memory) CreateChirpMentions(ctx context.Context, arg database.CreateChirpMentionsParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.chirps[arg.ChirpID]; !ok {
+        return fmt.Errorf("mentions in unknown chirp %s", arg.ChirpID)
+    }
+    for _, userID := range arg.UserIds {
+        if _, ok := m.users[userID]; ok && !m.blockedBetween(arg.AuthorID, userID) {
+            m.mentions[edge{arg.ChirpID, userID}] = true
+        }
+    }
+    return nil
+}
+
+func (m *Memory) CreateFollow(ctx context.Context, arg database.CreateFollowParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if err := m.requireUsers(arg.FollowerID, arg.FolloweeID); err != nil {
+        return err
+    }
+    m.follows[edge{arg.FollowerID, arg.FolloweeID}] = true
+    return nil
+}
+
+// CreateFollowRequest does nothing if the requester already follows the
+// target, as the query does.
+func (m *Memory) CreateFollowRequest(ctx context.Context, arg database.CreateFollowRequestParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if err := m.requireUsers(arg.RequesterID, arg.TargetID); err != nil {
+        return err
+    }
+    request := edge{arg.RequesterID, arg.TargetID}
+    if !m.follows[request] {
+        m.requests[request] = true
+    }
+    return nil
+}
+
+// CreateBlock also drops the follows and follow requests between the two
+// users, as the query does.
+func (m *Memory) CreateBlock(ctx context.Context, arg database.CreateBlockParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if err := m.requireUsers(arg.BlockerID, arg.BlockedID); err != nil {
+        return err
+    }
+    for _, e := range []edge{{arg.BlockerID, arg.BlockedID}, {arg.BlockedID, arg.BlockerID}} {
+        delete(m.follows, e)
+        delete(m.requests, e)
+    }
+    m.blocks[edge{arg.BlockerID, arg.BlockedID}] = true
+    return nil
+}
+
+func (m *Memory) CreateMute(ctx context.Context, arg database.CreateMuteParams) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if err := m.requireUsers(arg.MuterID, arg.MutedID); err != nil {
+        return err
+    }
+    m.mutes[edge{arg.MuterID, arg.MutedID}] = true
+    return nil
+}
+
+// blockedBetween reports whether either user has blocked the other. The
+// caller must hold m.mu.
+func (m *Memory) blockedBetween(a, b uuid.UUID) bool {
+    return m.blocks[edge{a, b}] || m.blocks[edge{b, a}]
+}
+
+// requireUsers fails, as a foreign key would, unless every id is a user.
+// The caller must hold m.mu.
+func (m *Memory) requireUsers(ids ...uuid.UUID) error {
+    for _, id := range ids {
+        if _, ok := m.users[id]; !ok {
+            return fmt.Errorf("unknown user %s", id)
+        }
+    }
+    return nil
+}
+
+// CountChirpsByAuthorSince counts deleted chirps too, as the query does.
+func (m *Memory) CountChirpsByAuthorSince(ctx context.Context, arg database.CountChirpsByAuthorSinceParams) (int64, error) {
+    return m.countChirps(func(chirp database.Chirp) bool {
+        return chirp.UserID == arg.UserID && !chirp.CreatedAt.Before(arg.Since)
+    }), nil
+}
+
+func (m *Memory) CountDuplicateChirpsSince(ctx context.Context, arg database.CountDuplicateChirpsSinceParams) (int64, error) {
+    return m.countChirps(func(chirp database.Chirp) bool {
+        return chirp.UserID == arg.UserID && chirp.Body == arg.Body && !chirp.CreatedAt.Before(arg.Since)
+    }), nil
+}
+
+// listChirps returns the chirps keep accepts, oldest first.
+func (m *Memory) listChirps(keep func(database.Chirp) bool) []database.Chirp {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    var chirps []database.Chirp
+    for _, chirp := range m.chirps {
+        if keep(chirp) {
+            chirps = append(chirps, chirp)
+        }
+    }
+    sort.Slice(chirps, func(i, j int) bool {
+        return chirps[i].CreatedAt.Before(chirps[j].CreatedAt)
+    })
+    return chirps
+}
+
+func (m *Memory) countChirps(match func(database.Chirp) bool) int64 {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    var n int64
+    for _, chirp := range m.chirps {
+        if match(chirp) {
+            n++
+        }
+    }
+    return n
+}
+
+// listed is visible for the chirp listings, which leave out unlisted chirps
+// and the authors viewerID has muted. The caller must hold m.mu.
+func (m *Memory) listed(chirp database.Chirp, viewerID uuid.UUID, now time.Time) bool {
+    return !m.mutes[edge{viewerID, chirp.UserID}] && m.visible(chirp, viewerID, now, false)
+}
+
+// visible mirrors the visibility rules of the chirp queries, with shadow
+// bans judged as of now. Unlisted chirps only show up when fetched by ID.
+// The caller must hold m.mu.
+func (m *Memory) visible(chirp database.Chirp, viewerID uuid.UUID, now time.Time, allowUnlisted bool) bool {
+    if chirp.DeletedAt.Valid {
+        return false
+    }
+    if m.blockedBetween(viewerID, chirp.UserID) {
+        return false
+    }
+    if chirp.UserID == viewerID {
+        return true
+    }
+    if chirp.HiddenAt.Valid {
+        return false
+    }
+
+    switch chirp.Visibility {
+    case "public":
+    case "unlisted":
+        if !allowUnlisted {
+            return false
+        }
+    case "followers":
+        if !m.follows[edge{viewerID, chirp.UserID}] {
+            return false
+        }
+    case "mentioned":
+        if !m.mentions[edge{chirp.ID, viewerID}] {
+            return false
+        }
+    default:
+        return false
+    }
+
+    author := m.users[chirp.UserID]
+    if author.IsPrivate && !m.follows[edge{viewerID, author.ID}] {
+        return false
+    }
+    shadowBanned := author.ShadowBannedAt.Valid &&
+        (!author.ShadowBannedUntil.Valid || author.ShadowBannedUntil.Time.After(now))
+    return !shadowBanned
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/database/sqlite"
+    "github.com/google/uuid"
+    "github.com/mattn/go-sqlite3"
+)
+
+// SQLite is the Store for single-node deployments that keep everything in
+// one SQLite file. The generated models match the Postgres ones field for
+// field, so rows convert directly.
+//
+// SQLite has no gen_random_uuid() or NOW(), so ids and timestamps the
+// Postgres queries fill in are generated here instead. Times are stored as
+// UTC text and compared as strings, which is why every time passed in is
+// converted to UTC first.
+type SQLite struct {
+    q *sqlite.Queries
+}
+
+var _ Store = SQLite{}
+
+func NewSQLite(q *sqlite.Queries) SQLite {
+    return SQLite{q: q}
+}
+
+func (s SQLite) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+    now := time.Now().UTC()
+    user, err := s.q.CreateUser(ctx, sqlite.CreateUserParams{
+        ID:             uuid.New(),
+        CreatedAt:      now,
+        UpdatedAt:      now,
+        Email:          arg.Email,
+        HashedPassword: arg.HashedPassword,
+    })
+    return database.User(user), translateSQLite(err)
+}
+
+func (s SQLite) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+    user, err := s.q.GetUserByID(ctx, id)
+    return database.User(user), err
+}
+
+func (s SQLite) GetUserByEmail(ctx context.Context, email string) (database.User, error) {
+    user, err := s.q.GetUserByEmail(ctx, email)
+    return database.User(user), err
+}
+
+func (s SQLite) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.UpdateUserRow, error) {
+    user, err := s.q.UpdateUser(ctx, sqlite.UpdateUserParams{
+        Email:          arg.Email,
+        HashedPassword: arg.HashedPassword,
+        UpdatedAt:      arg.UpdatedAt.UTC(),
+        ID:             arg.ID,
+    })
+    return database.UpdateUserRow(user), translateSQLite(err)
+}
+
+func (s SQLite) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) (database.SetUserRoleRow, error) {
+    user, err := s.q.SetUserRole(ctx, sqlite.SetUserRoleParams{
+        Role:      arg.Role,
+        UpdatedAt: time.Now().UTC(),
+        ID:        arg.ID,
+    })
+    return database.SetUserRoleRow(user), err
+}
+
+func (s SQLite) SetUserPrivacy(ctx context.Context, arg database.SetUserPrivacyParams) (database.SetUserPrivacyRow, error) {
+    user, err := s.q.SetUserPrivacy(ctx, sqlite.SetUserPrivacyParams{
+        IsPrivate: arg.IsPrivate,
+        UpdatedAt: time.Now().UTC(),
+        ID:        arg.ID,
+    })
+    return database.SetUserPrivacyRow(user), err
+}
+
+func (s SQLite) SuspendUser(ctx context.Context, arg database.SuspendUserParams) (int64, error) {
+    return s.q.SuspendUser(ctx, sqlite.SuspendUserParams{
+        Now:            time.Now().UTC(),
+        SuspendedUntil: utcNull(arg.SuspendedUntil),
+        ID:             arg.ID,
+    })
+}
+
+func (s SQLite) ShadowBanUser(ctx context.Context, arg database.ShadowBanUserParams) (int64, error) {
+    return s.q.ShadowBanUser(ctx, sqlite.ShadowBanUserParams{
+        Now:               time.Now().UTC(),
+        ShadowBannedUntil: utcNull(arg.ShadowBannedUntil),
+        ID:                arg.ID,
+    })
+}
+
+func (s SQLite) DeleteAllUsers(ctx context.Context) error {
+    return s.q.DeleteAllUsers(ctx)
+}
+
+func (s SQLite) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) error {
+    return s.q.CreateRefreshToken(ctx, sqlite.CreateRefreshTokenParams{
+        Token:     arg.Token,
+        CreatedAt: arg.CreatedAt.UTC(),
+        UpdatedAt: arg.UpdatedAt.UTC(),
+        UserID:    arg.UserID,
+        ExpiresAt: arg.ExpiresAt.UTC(),
+        RevokedAt: utcNull(arg.RevokedAt),
+    })
+}
+
+func (s SQLite) GetRefreshToken(ctx context.Context, token string) (database.RefreshToken, error) {
+    refreshToken, err := s.q.GetRefreshToken(ctx, token)
+    return database.RefreshToken(refreshToken), err
+}
+
+func (s SQLite) RevokeRefreshToken(ctx context.Context, arg database.RevokeRefreshTokenParams) error {
+    return s.q.RevokeRefreshToken(ctx, sqlite.RevokeRefreshTokenParams{
+        RevokedAt: utcNull(arg.RevokedAt),
+        UpdatedAt: arg.UpdatedAt.UTC(),
+        Token:     arg.Token,
+    })
+}
+
+func (s SQLite) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+    return s.q.RevokeAllRefreshTokensForUser(ctx, sqlite.RevokeAllRefreshTokensForUserParams{
+        Now:    time.Now().UTC(),
+        UserID: userID,
+    })
+}
+
+func (s SQLite) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+    chirp, err := s.q.CreateChirp(ctx, sqlite.CreateChirpParams{
+        ID:         arg.ID,
+        CreatedAt:  arg.CreatedAt.UTC(),
+        UpdatedAt:  arg.UpdatedAt.UTC(),
+        Body:       arg.Body,
+        UserID:     arg.UserID,
+        Visibility: arg.Visibility,
+    })
+    return database.Chirp(chirp), err
+}
+
+func (s SQLite) GetAllChirps(ctx context.Context, arg database.GetAllChirpsParams) ([]database.Chirp, error) {
+    chirps, err := s.q.GetAllChirps(ctx, sqlite.GetAllChirpsParams{
+        ViewerID: arg.ViewerID,
+        Now:      arg.Now.UTC(),
+    })
+    return convertChirps(chirps), err
+}
+
+func (s SQLite) GetChirpsByAuthor(ctx context.Context, arg database.GetChirpsByAuthorParams) ([]database.Chirp, error) {
+    chirps, err := s.q.GetChirpsByAuthor(ctx, sqlite.GetChirpsByAuthorParams{
+        UserID:   arg.UserID,
+        ViewerID: arg.ViewerID,
+        Now:      arg.Now.UTC(),
+    })
+    return convertChirps(chirps), err
+}
+
+func (s SQLite) GetChirpByID(ctx context.Context, arg database.GetChirpByIDParams) (database.Chirp, error) {
+    chirp, err := s.q.GetChirpByID(ctx, sqlite.GetChirpByIDParams{
+        ID:       arg.ID,
+        ViewerID: arg.ViewerID,
+        Now:      arg.Now.UTC(),
+    })
+    return database.Chirp(chirp), err
+}
+
+func (s SQLite) SoftDeleteChirp(ctx context.Context, arg database.SoftDeleteChirpParams) error {
+    return s.q.SoftDeleteChirp(ctx, sqlite.SoftDeleteChirpParams{
+        Now: arg.Now.UTC(),
+        ID:  arg.ID,
+    })
+}
+
+func (s SQLite) GetDeletedChirpsByAuthor(ctx context.Context, arg database.GetDeletedChirpsByAuthorParams) ([]database.Chirp, error) {
+    chirps, err := s.q.GetDeletedChirpsByAuthor(ctx, sqlite.GetDeletedChirpsByAuthorParams{
+        UserID:       arg.UserID,
+        DeletedSince: sql.NullTime{Time: arg.DeletedSince.UTC(), Valid: true},
+    })
+    return convertChirps(chirps), err
+}
+
+func (s SQLite) GetDeletedChirpByID(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+    chirp, err := s.q.GetDeletedChirpByID(ctx, id)
+    return database.Chirp(chirp), err
+}
+
+func (s SQLite) RestoreChirp(ctx context.Context, arg database.RestoreChirpParams) (database.Chirp, error) {
+    chirp, err := s.q.RestoreChirp(ctx, sqlite.RestoreChirpParams{
+        Now: arg.Now.UTC(),
+        ID:  arg.ID,
+    })
+    return database.Chirp(chirp), err
+}
+
+func (s SQLite) PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error) {
+    return s.q.PurgeDeletedChirps(ctx, sql.NullTime{Time: deletedBefore.UTC(), Valid: true})
+}
+
+func (s SQLite) CountChirpsByAuthorSince(ctx context.Context, arg database.CountChirpsByAuthorSinceParams) (int64, error) {
+    return s.q.CountChirpsByAuthorSince(ctx, sqlite.CountChirpsByAuthorSinceParams{
+        UserID: arg.UserID,
+        Since:  arg.Since.UTC(),
+    })
+}
+
+func (s SQLite) CountDuplicateChirpsSince(ctx context.Context, arg database.CountDuplicateChirpsSinceParams) (int64, error) {
+    return s.q.CountDuplicateChirpsSince(ctx, sqlite.CountDuplicateChirpsSinceParams{
+        UserID: arg.UserID,
+        Body:   arg.Body,
+        Since:  arg.Since.UTC(),
+    })
+}
+
+// CreateChirpMentions records one mention at a time, since SQLite has no
+// arrays to pass the ids in. Ids that are not users, or are blocked either
+// way by the author, are skipped.
+func (s SQLite) CreateChirpMentions(ctx context.Context, arg database.CreateChirpMentionsParams) error {
+    for _, userID := range arg.UserIds {
+        err := s.q.CreateChirpMention(ctx, sqlite.CreateChirpMentionParams{
+            ChirpID:  arg.ChirpID,
+            UserID:   userID,
+            AuthorID: arg.AuthorID,
+        })
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (s SQLite) CreateFollow(ctx context.Context, arg database.CreateFollowParams) error {
+    return s.q.CreateFollow(ctx, sqlite.CreateFollowParams{
+        FollowerID: arg.FollowerID,
+        FolloweeID: arg.FolloweeID,
+        CreatedAt:  time.Now().UTC(),
+    })
+}
+
+func (s SQLite) CreateFollowRequest(ctx context.Context, arg database.CreateFollowRequestParams) error {
+    return s.q.CreateFollowRequest(ctx, sqlite.CreateFollowRequestParams{
+        RequesterID: arg.RequesterID,
+        TargetID:    arg.TargetID,
+        CreatedAt:   time.Now().UTC(),
+    })
+}
+
+// CreateBlock also drops the follows and follow requests between the two
+// users, through a trigger in the schema.
+func (s SQLite) CreateBlock(ctx context.Context, arg database.CreateBlockParams) error {
+    return s.q.CreateBlock(ctx, sqlite.CreateBlockParams{
+        BlockerID: arg.BlockerID,
+        BlockedID: arg.BlockedID,
+        CreatedAt: time.Now().UTC(),
+    })
+}
+
+func (s SQLite) CreateMute(ctx context.Context, arg database.CreateMuteParams) error {
+    return s.q.CreateMute(ctx, sqlite.CreateMuteParams{
+        MuterID:   arg.MuterID,
+        MutedID:   arg.MutedID,
+        CreatedAt: time.Now().UTC(),
+    })
+}
+
+func convertChirps(chirps []sqlite.Chirp) []database.Chirp {
+    out := make([]database.Chirp, len(chirps))
+    for i, chirp := range chirps {
+        out[i] = database.Chirp(chirp)
+    }
+    return out
+}
+
+func utcNull(t sql.NullTime) sql.NullTime {
+    if t.Valid {
+        t.Time = t.Time.UTC()
+    }
+    return t
+}
+
+// translateSQLite is translate for SQLite, where the unique email shows up
+// as a UNIQUE constraint failure.
+func translateSQLite(err error) error {
+    var sqliteErr sqlite3.Error
+    if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+        return ErrEmailTaken
+    }
+    return err
+}
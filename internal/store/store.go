@@ -0,0 +1,90 @@
+// Package store is the storage layer behind the account, token and chirp
+// endpoints. Postgres, through the sqlc queries, is the normal backend;
+// Memory keeps everything in the process for tests and demos.
+package store
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/google/uuid"
+    "github.com/lib/pq"
+)
+
+// ErrEmailTaken is returned when creating or updating a user would give two
+// users the same email.
+var ErrEmailTaken = errors.New("email already in use")
+
+// Store holds users, their refresh tokens and their chirps, along with the
+// privacy settings, sanctions, follows, mentions, blocks and mutes that
+// decide who can sign in and who can see a chirp. Lookups that find
+// nothing return sql.ErrNoRows, as the sqlc queries do, and deleting users
+// deletes everything they own with them.
+type Store interface {
+    CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+    GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error)
+    GetUserByEmail(ctx context.Context, email string) (database.User, error)
+    UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.UpdateUserRow, error)
+    SetUserRole(ctx context.Context, arg database.SetUserRoleParams) (database.SetUserRoleRow, error)
+    SetUserPrivacy(ctx context.Context, arg database.SetUserPrivacyParams) (database.SetUserPrivacyRow, error)
+    SuspendUser(ctx context.Context, arg database.SuspendUserParams) (int64, error)
+    ShadowBanUser(ctx context.Context, arg database.ShadowBanUserParams) (int64, error)
+    DeleteAllUsers(ctx context.Context) error
+
+    CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) error
+    GetRefreshToken(ctx context.Context, token string) (database.RefreshToken, error)
+    RevokeRefreshToken(ctx context.Context, arg database.RevokeRefreshTokenParams) error
+    RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error
+
+    CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error)
+    GetAllChirps(ctx context.Context, arg database.GetAllChirpsParams) ([]database.Chirp, error)
+    GetChirpsByAuthor(ctx context.Context, arg database.GetChirpsByAuthorParams) ([]database.Chirp, error)
+    GetChirpByID(ctx context.Context, arg database.GetChirpByIDParams) (database.Chirp, error)
+    SoftDeleteChirp(ctx context.Context, arg database.SoftDeleteChirpParams) error
+    GetDeletedChirpsByAuthor(ctx context.Context, arg database.GetDeletedChirpsByAuthorParams) ([]database.Chirp, error)
+    GetDeletedChirpByID(ctx context.Context, id uuid.UUID) (database.Chirp, error)
+    RestoreChirp(ctx context.Context, arg database.RestoreChirpParams) (database.Chirp, error)
+    PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error)
+    CountChirpsByAuthorSince(ctx context.Context, arg database.CountChirpsByAuthorSinceParams) (int64, error)
+    CountDuplicateChirpsSince(ctx context.Context, arg database.CountDuplicateChirpsSinceParams) (int64, error)
+
+    CreateChirpMentions(ctx context.Context, arg database.CreateChirpMentionsParams) error
+    CreateFollow(ctx context.Context, arg database.CreateFollowParams) error
+    CreateFollowRequest(ctx context.Context, arg database.CreateFollowRequestParams) error
+    CreateBlock(ctx context.Context, arg database.CreateBlockParams) error
+    CreateMute(ctx context.Context, arg database.CreateMuteParams) error
+}
+
+// SQL is the Store backed by the generated queries. It only adds the
+// translation of driver errors into this package's errors.
+type SQL struct {
+    *database.Queries
+}
+
+var _ Store = SQL{}
+
+func NewSQL(q *database.Queries) SQL {
+    return SQL{Queries: q}
+}
+
+func (s SQL) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+    user, err := s.Queries.CreateUser(ctx, arg)
+    return user, translate(err)
+}
+
+func (s SQL) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.UpdateUserRow, error) {
+    user, err := s.Queries.UpdateUser(ctx, arg)
+    return user, translate(err)
+}
+
+// translate maps the only constraint users can trip, the unique email, to
+// ErrEmailTaken.
+func translate(err error) error {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+        return ErrEmailTaken
+    }
+    return err
+}
@@ -0,0 +1,493 @@
+package store
+
+import (
+    "context"
+    "database/sql"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/database/sqlite"
+    "github.com/KrishKoria/Chirpy/internal/migrations"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// backends are the stores every conformance test runs against. Postgres is
+// only tried when CHIRPY_TEST_DB_URL names a database the tests may wipe.
+var backends = []struct {
+    name string
+    open func(t *testing.T) Store
+}{
+    {"memory", func(t *testing.T) Store { return NewMemory() }},
+    {"sqlite", openSQLite},
+    {"postgres", openPostgres},
+}
+
+func openSQLite(t *testing.T) Store {
+    t.Helper()
+    db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "chirpy.db")+"?_foreign_keys=on")
+    require.NoError(t, err)
+    t.Cleanup(func() { db.Close() })
+
+    m, err := migrations.NewSQLite(db, os.DirFS("../../sql/sqlite/schema"))
+    require.NoError(t, err)
+    _, err = m.Up(context.Background())
+    require.NoError(t, err)
+    return NewSQLite(sqlite.New(db))
+}
+
+func openPostgres(t *testing.T) Store {
+    t.Helper()
+    url := os.Getenv("CHIRPY_TEST_DB_URL")
+    if url == "" {
+        t.Skip("CHIRPY_TEST_DB_URL not set")
+    }
+    db, err := sql.Open("postgres", url)
+    require.NoError(t, err)
+    t.Cleanup(func() { db.Close() })
+
+    m, err := migrations.New(db, os.DirFS("../../sql/schema"))
+    require.NoError(t, err)
+    _, err = m.Up(context.Background())
+    require.NoError(t, err)
+
+    s := NewSQL(database.New(db))
+    require.NoError(t, s.DeleteAllUsers(context.Background()))
+    return s
+}
+
+func TestStore(t *testing.T) {
+    tests := []struct {
+        name string
+        run  func(t *testing.T, s Store)
+    }{
+        {"UniqueEmail", testUniqueEmail},
+        {"SetUserRole", testSetUserRole},
+        {"DeleteAllUsersCascades", testDeleteAllUsersCascades},
+        {"RefreshTokens", testRefreshTokens},
+        {"ChirpVisibility", testChirpVisibility},
+        {"FollowersOnly", testFollowersOnly},
+        {"MentionedOnly", testMentionedOnly},
+        {"PrivateAccount", testPrivateAccount},
+        {"Blocks", testBlocks},
+        {"Mutes", testMutes},
+        {"Sanctions", testSanctions},
+        {"Trash", testTrash},
+    }
+
+    for _, backend := range backends {
+        t.Run(backend.name, func(t *testing.T) {
+            for _, tt := range tests {
+                t.Run(tt.name, func(t *testing.T) {
+                    tt.run(t, backend.open(t))
+                })
+            }
+        })
+    }
+}
+
+func testUniqueEmail(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    assert.NotEqual(t, uuid.Nil, alice.ID)
+    assert.Equal(t, auth.RoleUser, alice.Role)
+
+    _, err := s.CreateUser(ctx, database.CreateUserParams{Email: "alice@example.com", HashedPassword: "hash"})
+    assert.ErrorIs(t, err, ErrEmailTaken)
+
+    _, err = s.UpdateUser(ctx, database.UpdateUserParams{ID: bob.ID, Email: "alice@example.com", UpdatedAt: time.Now()})
+    assert.ErrorIs(t, err, ErrEmailTaken)
+
+    // Keeping your own email is not a conflict.
+    updated, err := s.UpdateUser(ctx, database.UpdateUserParams{ID: alice.ID, Email: "alice@example.com", HashedPassword: "new", UpdatedAt: time.Now()})
+    assert.NoError(t, err)
+    assert.Equal(t, alice.ID, updated.ID)
+
+    _, err = s.UpdateUser(ctx, database.UpdateUserParams{ID: uuid.New(), Email: "carol@example.com", UpdatedAt: time.Now()})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testSetUserRole(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+
+    updated, err := s.SetUserRole(ctx, database.SetUserRoleParams{ID: alice.ID, Role: auth.RoleAdmin})
+    assert.NoError(t, err)
+    assert.Equal(t, database.SetUserRoleRow{ID: alice.ID, Email: alice.Email, Role: auth.RoleAdmin}, updated)
+
+    user, err := s.GetUserByID(ctx, alice.ID)
+    assert.NoError(t, err)
+    assert.Equal(t, auth.RoleAdmin, user.Role)
+
+    _, err = s.SetUserRole(ctx, database.SetUserRoleParams{ID: uuid.New(), Role: auth.RoleAdmin})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteAllUsersCascades(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    chirp := createChirp(t, s, alice.ID, "hello", "public")
+    now := time.Now()
+    assert.NoError(t, s.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+        Token:     "token",
+        CreatedAt: now,
+        UpdatedAt: now,
+        UserID:    alice.ID,
+        ExpiresAt: now.Add(time.Hour),
+    }))
+
+    assert.NoError(t, s.DeleteAllUsers(ctx))
+
+    _, err := s.GetChirpByID(ctx, database.GetChirpByIDParams{ID: chirp.ID, ViewerID: alice.ID, Now: time.Now()})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+    _, err = s.GetRefreshToken(ctx, "token")
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+    _, err = s.GetUserByEmail(ctx, "alice@example.com")
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testRefreshTokens(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    now := time.Now()
+
+    err := s.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "orphan", UserID: uuid.New(), CreatedAt: now, UpdatedAt: now, ExpiresAt: now})
+    assert.Error(t, err)
+
+    for _, token := range []string{"one", "two"} {
+        assert.NoError(t, s.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+            Token:     token,
+            CreatedAt: now,
+            UpdatedAt: now,
+            UserID:    alice.ID,
+            ExpiresAt: now.Add(time.Hour),
+        }))
+    }
+    assert.NoError(t, s.RevokeAllRefreshTokensForUser(ctx, alice.ID))
+
+    for _, token := range []string{"one", "two"} {
+        refreshToken, err := s.GetRefreshToken(ctx, token)
+        assert.NoError(t, err)
+        assert.True(t, refreshToken.RevokedAt.Valid)
+        assert.WithinDuration(t, now.Add(time.Hour), refreshToken.ExpiresAt, time.Millisecond)
+    }
+}
+
+func testChirpVisibility(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+
+    public := createChirp(t, s, alice.ID, "public", "public")
+    unlisted := createChirp(t, s, alice.ID, "unlisted", "unlisted")
+    followers := createChirp(t, s, alice.ID, "followers", "followers")
+    deleted := createChirp(t, s, alice.ID, "deleted", "public")
+    assert.NoError(t, s.SoftDeleteChirp(ctx, database.SoftDeleteChirpParams{Now: time.Now().UTC(), ID: deleted.ID}))
+
+    own, err := s.GetChirpsByAuthor(ctx, database.GetChirpsByAuthorParams{UserID: alice.ID, ViewerID: alice.ID, Now: time.Now()})
+    assert.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{public.ID, unlisted.ID, followers.ID}, chirpIDs(own))
+
+    listed, err := s.GetAllChirps(ctx, database.GetAllChirpsParams{ViewerID: bob.ID, Now: time.Now()})
+    assert.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{public.ID}, chirpIDs(listed))
+
+    _, err = s.GetChirpByID(ctx, database.GetChirpByIDParams{ID: unlisted.ID, ViewerID: bob.ID, Now: time.Now()})
+    assert.NoError(t, err)
+    _, err = s.GetChirpByID(ctx, database.GetChirpByIDParams{ID: followers.ID, ViewerID: bob.ID, Now: time.Now()})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+
+    // Deleted chirps still count towards the posting rate.
+    count, err := s.CountChirpsByAuthorSince(ctx, database.CountChirpsByAuthorSinceParams{
+        UserID: alice.ID,
+        Since:  time.Now().Add(-time.Minute),
+    })
+    assert.NoError(t, err)
+    assert.Equal(t, int64(4), count)
+
+    count, err = s.CountDuplicateChirpsSince(ctx, database.CountDuplicateChirpsSinceParams{
+        UserID: alice.ID,
+        Body:   "public",
+        Since:  time.Now().Add(-time.Minute),
+    })
+    assert.NoError(t, err)
+    assert.Equal(t, int64(1), count)
+}
+
+func testFollowersOnly(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    carol := createUser(t, s, "carol@example.com")
+
+    public := createChirp(t, s, alice.ID, "public", "public")
+    followers := createChirp(t, s, alice.ID, "followers", "followers")
+    reply := createChirp(t, s, bob.ID, "reply", "followers")
+    require.NoError(t, s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: bob.ID, FolloweeID: alice.ID}))
+
+    assert.Equal(t, []uuid.UUID{public.ID, followers.ID, reply.ID}, visibleChirps(t, s, bob.ID, alice.ID, bob.ID))
+    assert.True(t, canFetch(t, s, bob.ID, followers.ID))
+
+    assert.Equal(t, []uuid.UUID{public.ID}, visibleChirps(t, s, carol.ID, alice.ID, bob.ID))
+    assert.False(t, canFetch(t, s, carol.ID, followers.ID))
+
+    // Following is one way: bob's followers do not include alice.
+    assert.Equal(t, []uuid.UUID{public.ID, followers.ID}, visibleChirps(t, s, alice.ID, alice.ID, bob.ID))
+    assert.False(t, canFetch(t, s, alice.ID, reply.ID))
+}
+
+func testMentionedOnly(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    carol := createUser(t, s, "carol@example.com")
+
+    mentioned := createChirp(t, s, alice.ID, "hi bob", "mentioned")
+    require.NoError(t, s.CreateChirpMentions(ctx, database.CreateChirpMentionsParams{
+        ChirpID:  mentioned.ID,
+        UserIds:  []uuid.UUID{bob.ID, uuid.New()},
+        AuthorID: alice.ID,
+    }))
+
+    assert.Equal(t, []uuid.UUID{mentioned.ID}, visibleChirps(t, s, bob.ID, alice.ID, bob.ID))
+    assert.True(t, canFetch(t, s, bob.ID, mentioned.ID))
+
+    // Following the author does not reveal chirps meant for others.
+    require.NoError(t, s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: carol.ID, FolloweeID: alice.ID}))
+    assert.Empty(t, visibleChirps(t, s, carol.ID, alice.ID, bob.ID))
+    assert.False(t, canFetch(t, s, carol.ID, mentioned.ID))
+}
+
+func testPrivateAccount(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    carol := createUser(t, s, "carol@example.com")
+
+    updated, err := s.SetUserPrivacy(ctx, database.SetUserPrivacyParams{ID: alice.ID, IsPrivate: true})
+    require.NoError(t, err)
+    assert.True(t, updated.IsPrivate)
+    _, err = s.SetUserPrivacy(ctx, database.SetUserPrivacyParams{ID: uuid.New(), IsPrivate: true})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+
+    public := createChirp(t, s, alice.ID, "public", "public")
+    unlisted := createChirp(t, s, alice.ID, "unlisted", "unlisted")
+    require.NoError(t, s.CreateFollowRequest(ctx, database.CreateFollowRequestParams{RequesterID: bob.ID, TargetID: alice.ID}))
+    require.NoError(t, s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: carol.ID, FolloweeID: alice.ID}))
+
+    // A pending request is not a follow.
+    assert.Empty(t, visibleChirps(t, s, bob.ID, alice.ID))
+    assert.False(t, canFetch(t, s, bob.ID, public.ID))
+    assert.False(t, canFetch(t, s, bob.ID, unlisted.ID))
+
+    assert.Equal(t, []uuid.UUID{public.ID}, visibleChirps(t, s, carol.ID, alice.ID))
+    assert.True(t, canFetch(t, s, carol.ID, unlisted.ID))
+    assert.Equal(t, []uuid.UUID{public.ID, unlisted.ID}, visibleChirps(t, s, alice.ID, alice.ID))
+
+    _, err = s.SetUserPrivacy(ctx, database.SetUserPrivacyParams{ID: alice.ID, IsPrivate: false})
+    require.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{public.ID}, visibleChirps(t, s, bob.ID, alice.ID))
+}
+
+func testBlocks(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    carol := createUser(t, s, "carol@example.com")
+
+    fromAlice := createChirp(t, s, alice.ID, "alice", "public")
+    fromBob := createChirp(t, s, bob.ID, "bob", "public")
+    fromCarol := createChirp(t, s, carol.ID, "carol", "public")
+    require.NoError(t, s.CreateBlock(ctx, database.CreateBlockParams{BlockerID: alice.ID, BlockedID: bob.ID}))
+
+    // The block hides each user's chirps from the other, whoever blocked.
+    assert.Equal(t, []uuid.UUID{fromAlice.ID, fromCarol.ID}, visibleChirps(t, s, alice.ID, alice.ID, bob.ID, carol.ID))
+    assert.False(t, canFetch(t, s, alice.ID, fromBob.ID))
+    assert.Equal(t, []uuid.UUID{fromBob.ID, fromCarol.ID}, visibleChirps(t, s, bob.ID, alice.ID, bob.ID, carol.ID))
+    assert.False(t, canFetch(t, s, bob.ID, fromAlice.ID))
+    assert.Equal(t, []uuid.UUID{fromAlice.ID, fromBob.ID, fromCarol.ID}, visibleChirps(t, s, carol.ID, alice.ID, bob.ID, carol.ID))
+}
+
+func testMutes(t *testing.T, s Store) {
+    ctx := context.Background()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+
+    fromAlice := createChirp(t, s, alice.ID, "alice", "public")
+    fromBob := createChirp(t, s, bob.ID, "bob", "public")
+    require.NoError(t, s.CreateMute(ctx, database.CreateMuteParams{MuterID: alice.ID, MutedID: bob.ID}))
+
+    // Muting only filters the muter's listings.
+    assert.Equal(t, []uuid.UUID{fromAlice.ID}, visibleChirps(t, s, alice.ID, alice.ID, bob.ID))
+    assert.True(t, canFetch(t, s, alice.ID, fromBob.ID))
+    assert.Equal(t, []uuid.UUID{fromAlice.ID, fromBob.ID}, visibleChirps(t, s, bob.ID, alice.ID, bob.ID))
+}
+
+func testSanctions(t *testing.T, s Store) {
+    ctx := context.Background()
+    now := time.Now().UTC()
+    alice := createUser(t, s, "alice@example.com")
+    bob := createUser(t, s, "bob@example.com")
+    carol := createUser(t, s, "carol@example.com")
+
+    n, err := s.SuspendUser(ctx, database.SuspendUserParams{
+        ID:             bob.ID,
+        SuspendedUntil: sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+    })
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), n)
+    user, err := s.GetUserByID(ctx, bob.ID)
+    require.NoError(t, err)
+    assert.True(t, user.SuspendedAt.Valid)
+    assert.WithinDuration(t, now.Add(time.Hour), user.SuspendedUntil.Time, time.Second)
+
+    fromAlice := createChirp(t, s, alice.ID, "alice", "public")
+    fromCarol := createChirp(t, s, carol.ID, "carol", "unlisted")
+    n, err = s.ShadowBanUser(ctx, database.ShadowBanUserParams{
+        ID:                alice.ID,
+        ShadowBannedUntil: sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+    })
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), n)
+    _, err = s.ShadowBanUser(ctx, database.ShadowBanUserParams{ID: carol.ID})
+    require.NoError(t, err)
+
+    // A shadow-banned author still sees their own chirps; nobody else does.
+    assert.Equal(t, []uuid.UUID{fromAlice.ID}, visibleChirps(t, s, alice.ID, alice.ID))
+    assert.Empty(t, visibleChirps(t, s, bob.ID, alice.ID, carol.ID))
+    assert.False(t, canFetch(t, s, bob.ID, fromAlice.ID))
+    assert.False(t, canFetch(t, s, bob.ID, fromCarol.ID))
+
+    // Once the shadow-ban ends the chirps are back; carol's has no end.
+    _, err = s.ShadowBanUser(ctx, database.ShadowBanUserParams{
+        ID:                alice.ID,
+        ShadowBannedUntil: sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+    })
+    require.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{fromAlice.ID}, visibleChirps(t, s, bob.ID, alice.ID, carol.ID))
+    assert.False(t, canFetch(t, s, bob.ID, fromCarol.ID))
+
+    n, err = s.SuspendUser(ctx, database.SuspendUserParams{ID: uuid.New()})
+    require.NoError(t, err)
+    assert.Equal(t, int64(0), n)
+    n, err = s.ShadowBanUser(ctx, database.ShadowBanUserParams{ID: uuid.New()})
+    require.NoError(t, err)
+    assert.Equal(t, int64(0), n)
+}
+
+func testTrash(t *testing.T, s Store) {
+    ctx := context.Background()
+    now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+    alice := createUser(t, s, "alice@example.com")
+    kept := createChirp(t, s, alice.ID, "kept", "public")
+    restored := createChirp(t, s, alice.ID, "restored", "public")
+    purged := createChirp(t, s, alice.ID, "purged", "public")
+    assert.NoError(t, s.SoftDeleteChirp(ctx, database.SoftDeleteChirpParams{Now: now.Add(-time.Hour), ID: restored.ID}))
+    assert.NoError(t, s.SoftDeleteChirp(ctx, database.SoftDeleteChirpParams{Now: now.Add(-48 * time.Hour), ID: purged.ID}))
+
+    trash, err := s.GetDeletedChirpsByAuthor(ctx, database.GetDeletedChirpsByAuthorParams{
+        UserID:       alice.ID,
+        DeletedSince: now.Add(-24 * time.Hour),
+    })
+    assert.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{restored.ID}, chirpIDs(trash))
+
+    // Most recently deleted first.
+    trash, err = s.GetDeletedChirpsByAuthor(ctx, database.GetDeletedChirpsByAuthorParams{
+        UserID:       alice.ID,
+        DeletedSince: now.Add(-72 * time.Hour),
+    })
+    assert.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{restored.ID, purged.ID}, chirpIDs(trash))
+
+    chirp, err := s.GetDeletedChirpByID(ctx, purged.ID)
+    assert.NoError(t, err)
+    assert.True(t, chirp.DeletedAt.Time.Equal(now.Add(-48*time.Hour)), "deleted at %v", chirp.DeletedAt.Time)
+    _, err = s.GetDeletedChirpByID(ctx, kept.ID)
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+
+    chirp, err = s.RestoreChirp(ctx, database.RestoreChirpParams{Now: now, ID: restored.ID})
+    assert.NoError(t, err)
+    assert.False(t, chirp.DeletedAt.Valid)
+    assert.True(t, chirp.UpdatedAt.Equal(now), "updated at %v", chirp.UpdatedAt)
+    _, err = s.RestoreChirp(ctx, database.RestoreChirpParams{Now: now, ID: restored.ID})
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+
+    n, err := s.PurgeDeletedChirps(ctx, now.Add(-72*time.Hour))
+    assert.NoError(t, err)
+    assert.Equal(t, int64(0), n)
+    n, err = s.PurgeDeletedChirps(ctx, now.Add(-24*time.Hour))
+    assert.NoError(t, err)
+    assert.Equal(t, int64(1), n)
+
+    _, err = s.GetDeletedChirpByID(ctx, purged.ID)
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+    own, err := s.GetChirpsByAuthor(ctx, database.GetChirpsByAuthorParams{UserID: alice.ID, ViewerID: alice.ID, Now: time.Now()})
+    assert.NoError(t, err)
+    assert.Equal(t, []uuid.UUID{kept.ID, restored.ID}, chirpIDs(own))
+}
+
+func createUser(t *testing.T, s Store, email string) database.User {
+    t.Helper()
+    user, err := s.CreateUser(context.Background(), database.CreateUserParams{Email: email, HashedPassword: "hash"})
+    require.NoError(t, err)
+    return user
+}
+
+func createChirp(t *testing.T, s Store, userID uuid.UUID, body, visibility string) database.Chirp {
+    t.Helper()
+    now := time.Now()
+    chirp, err := s.CreateChirp(context.Background(), database.CreateChirpParams{
+        ID:         uuid.New(),
+        CreatedAt:  now,
+        UpdatedAt:  now,
+        Body:       body,
+        UserID:     userID,
+        Visibility: visibility,
+    })
+    require.NoError(t, err)
+    return chirp
+}
+
+// visibleChirps lists what viewerID sees of everyone's chirps, checking that
+// listing each of authors' chirps agrees.
+func visibleChirps(t *testing.T, s Store, viewerID uuid.UUID, authors ...uuid.UUID) []uuid.UUID {
+    t.Helper()
+    ctx := context.Background()
+    chirps, err := s.GetAllChirps(ctx, database.GetAllChirpsParams{ViewerID: viewerID, Now: time.Now().UTC()})
+    require.NoError(t, err)
+
+    var byAuthor []database.Chirp
+    for _, authorID := range authors {
+        listed, err := s.GetChirpsByAuthor(ctx, database.GetChirpsByAuthorParams{UserID: authorID, ViewerID: viewerID, Now: time.Now().UTC()})
+        require.NoError(t, err)
+        byAuthor = append(byAuthor, listed...)
+    }
+    assert.ElementsMatch(t, chirpIDs(chirps), chirpIDs(byAuthor), "listing by author")
+    return chirpIDs(chirps)
+}
+
+// canFetch reports whether viewerID can look chirpID up directly.
+func canFetch(t *testing.T, s Store, viewerID, chirpID uuid.UUID) bool {
+    t.Helper()
+    _, err := s.GetChirpByID(context.Background(), database.GetChirpByIDParams{ID: chirpID, ViewerID: viewerID, Now: time.Now().UTC()})
+    if err == sql.ErrNoRows {
+        return false
+    }
+    require.NoError(t, err)
+    return true
+}
+
+func chirpIDs(chirps []database.Chirp) []uuid.UUID {
+    ids := make([]uuid.UUID, len(chirps))
+    for i, chirp := range chirps {
+        ids[i] = chirp.ID
+    }
+    return ids
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+    "context"
+    "database/sql"
+    "strings"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// DBTX matches the interface sqlc generates in internal/database.
+type DBTX interface {
+    ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+    PrepareContext(context.Context, string) (*sql.Stmt, error)
+    QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+    QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// WrapDB returns a DBTX that records a child span for every query, named
+// after the sqlc query that issued it and tagged with system, such as
+// semconv.DBSystemPostgreSQL.
+func WrapDB(db DBTX, system attribute.KeyValue) DBTX {
+    return &tracedDB{db: db, system: system}
+}
+
+type tracedDB struct {
+    db     DBTX
+    system attribute.KeyValue
+}
+
+func (t *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    ctx, span := t.startQuery(ctx, query)
+    result, err := t.db.ExecContext(ctx, query, args...)
+    endQuery(span, err)
+    return result, err
+}
+
+func (t *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+    ctx, span := t.startQuery(ctx, query)
+    stmt, err := t.db.PrepareContext(ctx, query)
+    endQuery(span, err)
+    return stmt, err
+}
+
+func (t *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    ctx, span := t.startQuery(ctx, query)
+    rows, err := t.db.QueryContext(ctx, query, args...)
+    endQuery(span, err)
+    return rows, err
+}
+
+func (t *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    ctx, span := t.startQuery(ctx, query)
+    row := t.db.QueryRowContext(ctx, query, args...)
+    endQuery(span, row.Err())
+    return row
+}
+
+func (t *tracedDB) startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+    name := QueryName(query)
+    return tracer().Start(ctx, name,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            t.system,
+            attribute.String("db.operation.name", name),
+        ),
+    )
+}
+
+func endQuery(span trace.Span, err error) {
+    // A missing row is an expected answer, not a failed query.
+    if err != nil && err != sql.ErrNoRows {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
+
+// QueryName extracts the query name from the "-- name: X :kind" comment sqlc
+// puts at the top of every query, falling back to "db.query".
+func QueryName(query string) string {
+    line, _, _ := strings.Cut(strings.TrimSpace(query), "\n")
+    rest, ok := strings.CutPrefix(line, "-- name: ")
+    if !ok {
+        return "db.query"
+    }
+    name, _, _ := strings.Cut(rest, " ")
+    return name
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "net/url"
+    "os"
+
+    "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+    "go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/KrishKoria/Chirpy"
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation. Spans are only exported when an OTLP endpoint is configured
+// through the standard OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables; otherwise the no-op provider
+// stays in place. The returned function flushes pending spans.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+        propagation.TraceContext{},
+        propagation.Baggage{},
+    ))
+
+    if !exportEnabled() {
+        return func(context.Context) error { return nil }, nil
+    }
+
+    exporter, err := otlptracehttp.New(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    // Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win over
+    // the default service name.
+    res, err := resource.New(ctx,
+        resource.WithAttributes(semconv.ServiceName(serviceName)),
+        resource.WithTelemetrySDK(),
+        resource.WithFromEnv(),
+    )
+    if err != nil {
+        return nil, err
+    }
+
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+    )
+    otel.SetTracerProvider(provider)
+    return provider.Shutdown, nil
+}
+
+func exportEnabled() bool {
+    if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+        return false
+    }
+    return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+        os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// ExporterAddress returns the host:port spans are exported to, or "" when
+// export is disabled.
+func ExporterAddress() string {
+    if !exportEnabled() {
+        return ""
+    }
+    endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+    if endpoint == "" {
+        endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+    }
+    u, err := url.Parse(endpoint)
+    if err != nil || u.Host == "" {
+        return ""
+    }
+    if u.Port() != "" {
+        return u.Host
+    }
+    if u.Scheme == "https" {
+        return net.JoinHostPort(u.Hostname(), "443")
+    }
+    return net.JoinHostPort(u.Hostname(), "80")
+}
+
+func tracer() trace.Tracer {
+    return otel.Tracer(instrumentationName)
+}
+
+// Middleware starts a server span for every request, continuing the caller's
+// trace when it sends a traceparent header. The span is named after the
+// matched route by Route, which must wrap the ServeMux itself.
+func Middleware(next http.Handler) http.Handler {
+    return otelhttp.NewHandler(next, "http.request")
+}
+
+// Route names the request's server span after the route the ServeMux
+// matched. It has to wrap the mux directly: the mux sets Pattern on the
+// request it is given, and middleware that calls WithContext in between
+// hands it a copy the outer layers never see.
+func Route(mux http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mux.ServeHTTP(w, r)
+
+        if r.Pattern != "" {
+            span := trace.SpanFromContext(r.Context())
+            span.SetName(r.Pattern)
+            span.SetAttributes(semconv.HTTPRoute(r.Pattern))
+        }
+    })
+}
+
+// Transport wraps base so outgoing requests get a client span and carry the
+// current trace context.
+func Transport(base http.RoundTripper) http.RoundTripper {
+    return otelhttp.NewTransport(base)
+}
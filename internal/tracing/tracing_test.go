@@ -0,0 +1,104 @@
+package tracing
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/propagation"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+    recorder := tracetest.NewSpanRecorder()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+    previous := otel.GetTracerProvider()
+    otel.SetTracerProvider(provider)
+    otel.SetTextMapPropagator(propagation.TraceContext{})
+    t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+    return recorder
+}
+
+type fakeDB struct {
+    DBTX
+    err error
+}
+
+func (f fakeDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    return nil, f.err
+}
+
+func TestQueryName(t *testing.T) {
+    assert.Equal(t, "GetUserByID", QueryName("-- name: GetUserByID :one\nSELECT * FROM users WHERE id = $1"))
+    assert.Equal(t, "db.query", QueryName("SELECT 1"))
+}
+
+func TestWrapDBRecordsSpans(t *testing.T) {
+    recorder := recordSpans(t)
+
+    db := WrapDB(fakeDB{}, semconv.DBSystemPostgreSQL)
+    _, err := db.ExecContext(context.Background(), "-- name: DeleteAllUsers :exec\nDELETE FROM users")
+    assert.NoError(t, err)
+
+    failing := WrapDB(fakeDB{err: errors.New("connection refused")}, semconv.DBSystemSqlite)
+    _, err = failing.ExecContext(context.Background(), "-- name: CreateUser :one\nINSERT INTO users")
+    assert.Error(t, err)
+
+    spans := recorder.Ended()
+    assert.Len(t, spans, 2)
+    assert.Equal(t, "DeleteAllUsers", spans[0].Name())
+    assert.Equal(t, codes.Unset, spans[0].Status().Code)
+    assert.Contains(t, spans[0].Attributes(), semconv.DBSystemPostgreSQL)
+    assert.Equal(t, "CreateUser", spans[1].Name())
+    assert.Equal(t, codes.Error, spans[1].Status().Code)
+    assert.Contains(t, spans[1].Attributes(), semconv.DBSystemSqlite)
+}
+
+func TestMiddlewareContinuesTraceAndNamesRoute(t *testing.T) {
+    recorder := recordSpans(t)
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {
+        // Queries made while handling the request are children of its span.
+        WrapDB(fakeDB{}, semconv.DBSystemPostgreSQL).ExecContext(r.Context(), "-- name: GetChirpByID :one\nSELECT 1")
+    })
+
+    req := httptest.NewRequest(http.MethodGet, "/api/chirps/123", nil)
+    req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+    // Middleware that swaps the request in between must not lose the route.
+    withContext := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        Route(mux).ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), struct{}{}, true)))
+    })
+    Middleware(withContext).ServeHTTP(httptest.NewRecorder(), req)
+
+    spans := recorder.Ended()
+    assert.Len(t, spans, 2)
+
+    query, server := spans[0], spans[1]
+    assert.Equal(t, "GET /api/chirps/{chirpID}", server.Name())
+    assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+    assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+    assert.Equal(t, server.SpanContext().SpanID(), query.Parent().SpanID())
+}
+
+func TestExporterAddress(t *testing.T) {
+    t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+    t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+    assert.Equal(t, "", ExporterAddress())
+
+    t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+    assert.Equal(t, "collector:4318", ExporterAddress())
+
+    t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://traces.example.com/v1/traces")
+    assert.Equal(t, "traces.example.com:443", ExporterAddress())
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/KrishKoria/Chirpy/internal/moderation"
+	"github.com/google/uuid"
+)
+
+const (
+    keywordActionHide     = "hide"
+    keywordActionCollapse = "collapse"
+)
+
+type keywordFilterResponse struct {
+    ID        uuid.UUID  `json:"id"`
+    Phrase    string     `json:"phrase"`
+    WholeWord bool       `json:"whole_word"`
+    Action    string     `json:"action"`
+    ExpiresAt *time.Time `json:"expires_at,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+func mapKeywordFilter(filter database.KeywordFilter) keywordFilterResponse {
+    response := keywordFilterResponse{
+        ID:        filter.ID,
+        Phrase:    filter.Phrase,
+        WholeWord: filter.WholeWord,
+        Action:    filter.Action,
+        CreatedAt: filter.CreatedAt,
+    }
+    if filter.ExpiresAt.Valid {
+        expiresAt := filter.ExpiresAt.Time
+        response.ExpiresAt = &expiresAt
+    }
+    return response
+}
+
+// activeKeywordFilters returns the viewer's unexpired filters. Anonymous
+// viewers have none.
+func (cfg *APIConfig) activeKeywordFilters(ctx context.Context, viewerID uuid.UUID) ([]database.KeywordFilter, error) {
+    if viewerID == uuid.Nil {
+        return nil, nil
+    }
+    return cfg.Extras.ListActiveKeywordFilters(ctx, database.ListActiveKeywordFiltersParams{
+        UserID: viewerID,
+        Now:    time.Now().UTC(),
+    })
+}
+
+// matchKeywordFilter reports which of the viewer's filters, if any, body
+// matches.
+func matchKeywordFilter(body string, filters []database.KeywordFilter) *FilterMatch {
+    if len(filters) == 0 {
+        return nil
+    }
+
+    keywords := make([]moderation.Keyword, len(filters))
+    for i, filter := range filters {
+        keywords[i] = moderation.Keyword{Phrase: filter.Phrase, WholeWord: filter.WholeWord}
+    }
+
+    i := moderation.MatchKeyword(body, keywords)
+    if i < 0 {
+        return nil
+    }
+
+    return &FilterMatch{
+        FilterID: filters[i].ID,
+        Phrase:   filters[i].Phrase,
+        Action:   filters[i].Action,
+    }
+}
+
+func (cfg *APIConfig) listKeywordFiltersHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    filters, err := cfg.DB.ListKeywordFilters(r.Context(), userID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve filters", err)
+        return
+    }
+
+    response := []keywordFilterResponse{}
+    for _, filter := range filters {
+        response = append(response, mapKeywordFilter(filter))
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) createKeywordFilterHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    type filterRequest struct {
+        Phrase           string `json:"phrase"`
+        WholeWord        bool   `json:"whole_word"`
+        Action           string `json:"action"`
+        ExpiresInSeconds *int   `json:"expires_in_seconds,omitempty"`
+    }
+
+    var req filterRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    req.Phrase = strings.TrimSpace(req.Phrase)
+    if req.Phrase == "" {
+        respondWithError(w, http.StatusBadRequest, "Phrase is required")
+        return
+    }
+
+    if req.Action == "" {
+        req.Action = keywordActionHide
+    }
+    if req.Action != keywordActionHide && req.Action != keywordActionCollapse {
+        respondWithError(w, http.StatusBadRequest, "Action must be hide or collapse")
+        return
+    }
+
+    var expiresAt sql.NullTime
+    if req.ExpiresInSeconds != nil {
+        if *req.ExpiresInSeconds <= 0 {
+            respondWithError(w, http.StatusBadRequest, "expires_in_seconds must be positive")
+            return
+        }
+        expiresAt = sql.NullTime{
+            Time:  time.Now().UTC().Add(time.Duration(*req.ExpiresInSeconds) * time.Second),
+            Valid: true,
+        }
+    }
+
+    filter, err := cfg.DB.CreateKeywordFilter(r.Context(), database.CreateKeywordFilterParams{
+        UserID:    userID,
+        Phrase:    req.Phrase,
+        WholeWord: req.WholeWord,
+        Action:    req.Action,
+        ExpiresAt: expiresAt,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to create filter", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusCreated, mapKeywordFilter(filter))
+}
+
+func (cfg *APIConfig) deleteKeywordFilterHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    filterID, err := uuid.Parse(r.PathValue("filterID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid filter ID")
+        return
+    }
+
+    deleted, err := cfg.DB.DeleteKeywordFilter(r.Context(), database.DeleteKeywordFilterParams{
+        ID:     filterID,
+        UserID: userID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to delete filter", err)
+        return
+    }
+
+    if deleted == 0 {
+        respondWithError(w, http.StatusNotFound, "Filter not found")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+    "context"
+    "log/slog"
+    "net/http"
+    "time"
+
+    "github.com/google/uuid"
+    "go.opentelemetry.io/otel/trace"
+)
+
+const (
+    requestIDHeader    = "X-Request-ID"
+    maxRequestIDLength = 128
+
+    requestLogContextKey contextKey = "requestLog"
+)
+
+// requestLog collects what handlers learn about a request so the logging
+// middleware can include it in the access log line.
+type requestLog struct {
+    userID uuid.UUID
+}
+
+// setRequestUser records the authenticated user for the access log.
+func setRequestUser(ctx context.Context, userID uuid.UUID) {
+    if entry, ok := ctx.Value(requestLogContextKey).(*requestLog); ok {
+        entry.userID = userID
+    }
+}
+
+// logRecorder captures the status code and any error a handler reported
+// through respondWithInternalError.
+type logRecorder struct {
+    http.ResponseWriter
+    status      int
+    wroteHeader bool
+    err         error
+}
+
+func (rec *logRecorder) WriteHeader(status int) {
+    if !rec.wroteHeader {
+        rec.status = status
+        rec.wroteHeader = true
+    }
+    rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *logRecorder) Write(b []byte) (int, error) {
+    rec.wroteHeader = true
+    return rec.ResponseWriter.Write(b)
+}
+
+func (rec *logRecorder) Unwrap() http.ResponseWriter {
+    return rec.ResponseWriter
+}
+
+// middlewareRequestLog assigns each request an ID, reusing the caller's
+// X-Request-ID when it sends a usable one, echoes it on the response and
+// writes one log line per request once it completes.
+func (cfg *APIConfig) middlewareRequestLog(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requestID := r.Header.Get(requestIDHeader)
+        if !validRequestID(requestID) {
+            requestID = uuid.NewString()
+        }
+        w.Header().Set(requestIDHeader, requestID)
+
+        entry := &requestLog{}
+        r = r.WithContext(context.WithValue(r.Context(), requestLogContextKey, entry))
+        rec := &logRecorder{ResponseWriter: w, status: http.StatusOK}
+
+        start := time.Now()
+        next.ServeHTTP(rec, r)
+
+        attrs := []slog.Attr{
+            slog.String("request_id", requestID),
+            slog.String("method", r.Method),
+            slog.String("route", r.Pattern),
+            slog.String("path", r.URL.Path),
+            slog.Int("status", rec.status),
+            slog.Duration("latency", time.Since(start)),
+        }
+        if entry.userID != uuid.Nil {
+            attrs = append(attrs, slog.String("user_id", entry.userID.String()))
+        }
+        if span := trace.SpanContextFromContext(r.Context()); span.HasTraceID() {
+            attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+        }
+
+        level := slog.LevelInfo
+        if rec.status >= http.StatusInternalServerError {
+            level = slog.LevelError
+            if rec.err != nil {
+                attrs = append(attrs, slog.String("error", rec.err.Error()))
+            }
+        }
+        cfg.Logger.LogAttrs(r.Context(), level, "request", attrs...)
+    })
+}
+
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestIDLength {
+        return false
+    }
+    for _, c := range id {
+        if c < 0x21 || c > 0x7e {
+            return false
+        }
+    }
+    return true
+}
+
+// recordError hands err to the logging middleware, looking through any
+// writers wrapped around its recorder.
+func recordError(w http.ResponseWriter, err error) bool {
+    for {
+        if rec, ok := w.(*logRecorder); ok {
+            rec.err = err
+            return true
+        }
+        unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+        if !ok {
+            return false
+        }
+        w = unwrapper.Unwrap()
+    }
+}
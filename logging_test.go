@@ -0,0 +1,83 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// serveLogged sends req through the production middleware chain and returns
+// the response and the access log line.
+func serveLogged(t *testing.T, handler http.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, map[string]any) {
+    t.Helper()
+    var logs bytes.Buffer
+    cfg := &APIConfig{
+        Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+        Metrics: metrics.New(),
+    }
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /api/test", handler)
+
+    rec := httptest.NewRecorder()
+    cfg.handler(mux, 1<<20).ServeHTTP(rec, req)
+
+    var line map[string]any
+    require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+    return rec, line
+}
+
+func TestRequestLogRequestID(t *testing.T) {
+    ok := func(w http.ResponseWriter, r *http.Request) {}
+
+    req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+    req.Header.Set(requestIDHeader, "client-id-123")
+    rec, line := serveLogged(t, ok, req)
+    assert.Equal(t, "client-id-123", rec.Header().Get(requestIDHeader))
+    assert.Equal(t, "client-id-123", line["request_id"])
+
+    for _, id := range []string{"", "has space", strings.Repeat("x", maxRequestIDLength+1)} {
+        req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+        req.Header.Set(requestIDHeader, id)
+        rec, line := serveLogged(t, ok, req)
+
+        generated := rec.Header().Get(requestIDHeader)
+        _, err := uuid.Parse(generated)
+        assert.NoError(t, err, "request ID %q should be replaced", id)
+        assert.Equal(t, generated, line["request_id"])
+    }
+}
+
+func TestRequestLogUser(t *testing.T) {
+    userID := uuid.New()
+    _, line := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {
+        setRequestUser(r.Context(), userID)
+    }, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+
+    assert.Equal(t, "INFO", line["level"])
+    assert.Equal(t, float64(http.StatusOK), line["status"])
+    assert.Equal(t, userID.String(), line["user_id"])
+}
+
+// The error reaches the log line even though the metrics middleware wraps
+// the writer the handler is given.
+func TestRequestLogInternalError(t *testing.T) {
+    rec, line := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {
+        respondWithInternalError(w, "Failed to retrieve chirps", errors.New("connection refused"))
+    }, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+
+    assert.Equal(t, http.StatusInternalServerError, rec.Code)
+    assert.Equal(t, "ERROR", line["level"])
+    assert.Equal(t, float64(http.StatusInternalServerError), line["status"])
+    assert.Equal(t, "connection refused", line["error"])
+    assert.NotContains(t, rec.Body.String(), "connection refused")
+}
@@ -1,46 +1,107 @@
 package main
 
 import (
-	"database/sql"
-	"net/http"
-	"os"
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
 
-	"github.com/KrishKoria/Chirpy/internal/database"
-	"github.com/joho/godotenv"
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/config"
+    "github.com/KrishKoria/Chirpy/internal/entitlements"
+    "github.com/KrishKoria/Chirpy/internal/health"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/KrishKoria/Chirpy/internal/moderation"
+    "github.com/KrishKoria/Chirpy/internal/outbound"
+    "github.com/KrishKoria/Chirpy/internal/spam"
+    "github.com/KrishKoria/Chirpy/internal/tracing"
 )
 
-
 func main() {
-    err := godotenv.Load()
+    logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+    slog.SetDefault(logger)
+
+    appConfig, err := config.Load()
     if err != nil {
-        panic("Error loading .env file")
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
     }
 
-    dbURL := os.Getenv("DB_URL")
-    if dbURL == "" {
-        panic("DB_URL environment variable is not set")
-    }
-    db, err := sql.Open("postgres", dbURL)
+    shutdownTracing, err := tracing.Setup(context.Background(), "chirpy")
     if err != nil {
         panic(err)
     }
-    defer db.Close()
 
-    dbQueries := database.New(db)
     cfg := &APIConfig{
-        DB:       dbQueries,
-        Platform: os.Getenv("PLATFORM"),
-        JWTSecret: os.Getenv("JWT_SECRET"),
-        PolkaKey: os.Getenv("POLKA_KEY"),
+        Platform:          appConfig.Platform,
+        JWTSecret:         appConfig.JWTSecret,
+        AccessTokenTTL:    appConfig.AccessTokenTTL,
+        RefreshTokenTTL:   appConfig.RefreshTokenTTL,
+        PolkaWebhooks:     newPolkaVerifier(appConfig.PolkaKeys),
+        ChirpRetention:    30 * 24 * time.Hour,
+        SubscriptionGrace: 7 * 24 * time.Hour,
+        Profanity:         moderation.NewFilter(moderation.DefaultRules()),
+        Spam:              spam.NewDetector(spam.DefaultPolicy()),
+        Entitlements:      entitlements.NewRegistry(entitlements.DefaultPlans()),
+        Outbound:          outbound.NewSender(10 * time.Second),
+        Metrics:           metrics.New(),
+        Logger:            logger,
+    }
+
+    if err := cfg.openStorage(appConfig); err != nil {
+        panic(err)
+    }
+
+    if len(os.Args) > 1 {
+        err := errors.New("commands need a Postgres or SQLite DB_URL")
+        if cfg.Migrations != nil {
+            err = runCommand(context.Background(), cfg.Store, cfg.Migrations, os.Args[1:])
+        }
+        shutdownTracing(context.Background())
+        cfg.closeStorage()
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // In memory there is no schema to check, and without Postgres the
+    // built-in settings stay in force.
+    if cfg.Migrations != nil {
+        if err := cfg.prepareSchema(context.Background(), appConfig.AutoMigrate); err != nil {
+            cfg.Logger.Error("refusing to start", "error", err)
+            os.Exit(1)
+        }
+    }
+    if cfg.DB != nil {
+        if err := cfg.reloadProfanityFilter(context.Background()); err != nil {
+            panic(err)
+        }
+        if err := cfg.reloadSpamPolicy(context.Background()); err != nil {
+            panic(err)
+        }
+        if err := cfg.reloadEntitlements(context.Background()); err != nil {
+            panic(err)
+        }
+    }
+
+    if addr := tracing.ExporterAddress(); addr != "" {
+        cfg.HealthChecks = append(cfg.HealthChecks, health.Dial("otlp-exporter", addr))
     }
 
     mux := http.NewServeMux()
     mux.Handle("/app/", http.StripPrefix("/app", cfg.middlewareMetricsInc(http.FileServer(http.Dir("./app")))))
-    mux.HandleFunc("GET /api/healthz", ReadinessHandler)
-    mux.HandleFunc("GET /admin/metrics", cfg.MetricsHandler)
+    mux.HandleFunc("GET /api/healthz", LivenessHandler)
+    mux.HandleFunc("GET /api/readyz", cfg.readinessHandler)
+    mux.Handle("GET /metrics", cfg.Metrics.Handler())
     mux.HandleFunc("GET /api/chirps", cfg.getAllChirpsHandler)
     mux.HandleFunc("GET /api/chirps/{chirpID}", cfg.getChirpHandler)
-    mux.HandleFunc("POST /admin/reset", cfg.ResetHandler)
     mux.HandleFunc("POST /api/users", cfg.UsersHandler)
     mux.HandleFunc("POST /api/chirps", cfg.chirpsHandler)
     mux.HandleFunc("POST /api/login", cfg.loginHandler)
@@ -48,10 +109,125 @@ func main() {
     mux.HandleFunc("POST /api/revoke", cfg.revokeHandler)
     mux.HandleFunc("PUT /api/users", cfg.updateUserHandler)
     mux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.deleteChirpHandler)
-    mux.HandleFunc("POST /api/polka/webhooks", cfg.polkaWebhookHandler)
+    mux.HandleFunc("GET /api/chirps/trash", cfg.getTrashHandler)
+    mux.HandleFunc("POST /api/chirps/{chirpID}/restore", cfg.restoreChirpHandler)
+    mux.HandleFunc("GET /api/users/me/entitlements", cfg.getMyEntitlementsHandler)
+    mux.Handle("GET /admin/metrics", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.MetricsHandler)))
+    mux.Handle("POST /admin/reset", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.ResetHandler)))
+    mux.Handle("PUT /admin/users/{userID}/role", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.setUserRoleHandler)))
+
+    // Everything else is built on tables only the Postgres store has.
+    if cfg.DB != nil {
+        mux.Handle("GET /admin/profanity", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.listProfanityWordsHandler)))
+        mux.Handle("PUT /admin/profanity/{word}", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.upsertProfanityWordHandler)))
+        mux.Handle("GET /admin/spam-policy", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.getSpamPolicyHandler)))
+        mux.Handle("PUT /admin/spam-policy", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.updateSpamPolicyHandler)))
+        mux.Handle("GET /admin/entitlements", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.getEntitlementsHandler)))
+        mux.Handle("PUT /admin/entitlements", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.updateEntitlementsHandler)))
+        mux.Handle("GET /admin/webhook-endpoints", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.listGlobalWebhookEndpointsHandler)))
+        mux.Handle("POST /admin/webhook-endpoints", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.createGlobalWebhookEndpointHandler)))
+        mux.Handle("DELETE /admin/webhook-endpoints/{endpointID}", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.deleteGlobalWebhookEndpointHandler)))
+        mux.Handle("GET /admin/webhook-endpoints/{endpointID}/deliveries", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.listGlobalWebhookDeliveriesHandler)))
+        mux.Handle("GET /admin/webhooks", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.listWebhookEventsHandler)))
+        mux.Handle("GET /admin/webhooks/{eventID}", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.getWebhookEventHandler)))
+        mux.Handle("POST /admin/webhooks/{eventID}/replay", cfg.middlewareRequireRole(auth.RoleAdmin, http.HandlerFunc(cfg.replayWebhookEventHandler)))
+        mux.Handle("DELETE /admin/profanity/{word}", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.deleteProfanityWordHandler)))
+        mux.HandleFunc("POST /api/reports", cfg.createReportHandler)
+        mux.Handle("GET /api/admin/reports", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.listReportsHandler)))
+        mux.Handle("PATCH /api/admin/reports/{reportID}", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.updateReportHandler)))
+        mux.Handle("POST /api/admin/users/{userID}/suspend", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.suspendUserHandler)))
+        mux.Handle("DELETE /api/admin/users/{userID}/suspend", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.unsuspendUserHandler)))
+        mux.Handle("POST /api/admin/users/{userID}/shadow-ban", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.shadowBanUserHandler)))
+        mux.Handle("DELETE /api/admin/users/{userID}/shadow-ban", cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.unshadowBanUserHandler)))
+        mux.HandleFunc("POST /api/polka/webhooks", cfg.polkaWebhookHandler)
+        mux.HandleFunc("POST /api/users/{userID}/follow", cfg.followHandler)
+        mux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.unfollowHandler)
+        mux.HandleFunc("POST /api/users/{userID}/block", cfg.blockHandler)
+        mux.HandleFunc("DELETE /api/users/{userID}/block", cfg.unblockHandler)
+        mux.HandleFunc("POST /api/users/{userID}/mute", cfg.muteHandler)
+        mux.HandleFunc("DELETE /api/users/{userID}/mute", cfg.unmuteHandler)
+        mux.HandleFunc("GET /api/users/me/filters", cfg.listKeywordFiltersHandler)
+        mux.HandleFunc("POST /api/users/me/filters", cfg.createKeywordFilterHandler)
+        mux.HandleFunc("DELETE /api/users/me/filters/{filterID}", cfg.deleteKeywordFilterHandler)
+        mux.HandleFunc("GET /api/users/me/subscription", cfg.getSubscriptionHandler)
+        mux.HandleFunc("GET /api/users/me/webhooks", cfg.listMyWebhookEndpointsHandler)
+        mux.HandleFunc("POST /api/users/me/webhooks", cfg.createMyWebhookEndpointHandler)
+        mux.HandleFunc("DELETE /api/users/me/webhooks/{endpointID}", cfg.deleteMyWebhookEndpointHandler)
+        mux.HandleFunc("GET /api/users/me/webhooks/{endpointID}/deliveries", cfg.listMyWebhookDeliveriesHandler)
+        mux.HandleFunc("PUT /api/users/me/privacy", cfg.updatePrivacyHandler)
+        mux.HandleFunc("GET /api/users/me/follow-requests", cfg.getFollowRequestsHandler)
+        mux.HandleFunc("POST /api/users/me/follow-requests/{userID}/approve", cfg.approveFollowRequestHandler)
+        mux.HandleFunc("POST /api/users/me/follow-requests/{userID}/reject", cfg.rejectFollowRequestHandler)
+    }
     server := &http.Server{
-        Addr:    ":8080",
-        Handler: mux,
+        Addr:              appConfig.Addr(),
+        Handler:           cfg.handler(mux, appConfig.MaxBodyBytes),
+        ReadTimeout:       appConfig.ReadTimeout,
+        ReadHeaderTimeout: appConfig.ReadHeaderTimeout,
+        WriteTimeout:      appConfig.WriteTimeout,
+        IdleTimeout:       appConfig.IdleTimeout,
+        MaxHeaderBytes:    appConfig.MaxHeaderBytes,
+    }
+
+    // Workers are stopped in this order once the server has drained:
+    // producers of outbox events first, the delivery worker last so it
+    // stops after everything it might be asked to send.
+    cfg.Workers = []*worker{
+        startWorker("purge-chirps", func(ctx context.Context) { cfg.purgeExpiredChirps(ctx, time.Hour) }),
+    }
+    if cfg.DB != nil {
+        cfg.Workers = append(cfg.Workers,
+            startWorker("refresh-settings", func(ctx context.Context) { cfg.refreshSettings(ctx, time.Minute) }),
+            startWorker("expire-subscriptions", func(ctx context.Context) { cfg.expireSubscriptions(ctx, time.Hour) }),
+            startWorker("deliver-webhooks", func(ctx context.Context) { cfg.deliverWebhooks(ctx, 5*time.Second) }),
+        )
+    }
+    if cfg.Replicas != nil {
+        cfg.Workers = append([]*worker{
+            startWorker("check-replicas", func(ctx context.Context) { cfg.checkReplicas(ctx, 5*time.Second) }),
+        }, cfg.Workers...)
+    }
+
+    signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stopSignals()
+
+    serveErr := make(chan error, 1)
+    go func() {
+        cfg.Logger.Info("server listening", "addr", server.Addr)
+        serveErr <- server.ListenAndServe()
+    }()
+
+    failed := false
+    select {
+    case err := <-serveErr:
+        cfg.Logger.Error("server stopped", "error", err)
+        failed = true
+    case <-signals.Done():
+        cfg.Logger.Info("shutting down", "drain_timeout", appConfig.ShutdownTimeout)
+    }
+
+    drainCtx, cancelDrain := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+    defer cancelDrain()
+    if err := server.Shutdown(drainCtx); err != nil {
+        cfg.Logger.Error("drain requests", "error", err)
+        failed = true
+    }
+
+    workersCtx, cancelWorkers := context.WithTimeout(context.Background(), workerStopTimeout)
+    defer cancelWorkers()
+    cfg.stopWorkers(workersCtx, cfg.Workers)
+
+    tracingCtx, cancelTracing := context.WithTimeout(context.Background(), traceFlushTimeout)
+    defer cancelTracing()
+    if err := shutdownTracing(tracingCtx); err != nil {
+        cfg.Logger.Error("flush traces", "error", err)
+    }
+
+    // The database goes last so draining requests and stopping workers can
+    // still finish their queries.
+    cfg.closeStorage()
+
+    if failed {
+        os.Exit(1)
     }
-    server.ListenAndServe()
-}
\ No newline at end of file
+}
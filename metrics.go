@@ -11,20 +11,26 @@ func (cfg *APIConfig) middlewareMetricsInc(next http.Handler) http.Handler {
     })
 }
 
-func ReadinessHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-    w.WriteHeader(http.StatusOK)
-    w.Write([]byte("OK"))
-}
-
+// MetricsHandler renders a small human-readable dashboard. Scrapers should
+// use /metrics instead. The in-memory store has no connection pool to show.
 func (cfg *APIConfig) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+    pool := ""
+    if cfg.Conn != nil {
+        stats := cfg.Conn.Stats()
+        pool = fmt.Sprintf(`
+            <h2>Database</h2>
+            <p>%d open connections (%d in use, %d idle), %d waits for a connection.</p>`,
+            stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount)
+    }
+
     w.Header().Set("Content-Type", "text/html")
     w.WriteHeader(http.StatusOK)
     fmt.Fprintf(w, `
         <html>
           <body>
             <h1>Welcome, Chirpy Admin</h1>
-            <p>Chirpy has been visited %d times!</p>
+            <p>Chirpy has been visited %d times!</p>%s
+            <p>Prometheus metrics are available at <a href="/metrics">/metrics</a>.</p>
           </body>
-        </html>`, cfg.FileserverHits.Load())
-}
\ No newline at end of file
+        </html>`, cfg.FileserverHits.Load(), pool)
+}
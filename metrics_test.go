@@ -0,0 +1,23 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+// The in-memory backend has no *sql.DB, so the dashboard must leave the pool
+// stats out rather than panic.
+func TestMetricsHandlerWithoutConn(t *testing.T) {
+    cfg := &APIConfig{}
+    cfg.FileserverHits.Add(3)
+
+    rec := httptest.NewRecorder()
+    cfg.MetricsHandler(rec, httptest.NewRequest(http.MethodGet, "/admin/metrics", nil))
+
+    assert.Equal(t, http.StatusOK, rec.Code)
+    assert.Contains(t, rec.Body.String(), "visited 3 times")
+    assert.NotContains(t, rec.Body.String(), "open connections")
+}
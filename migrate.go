@@ -0,0 +1,96 @@
+package main
+
+import (
+    "context"
+    "embed"
+    "errors"
+    "fmt"
+    "io/fs"
+    "path/filepath"
+
+    "github.com/KrishKoria/Chirpy/internal/migrations"
+)
+
+//go:embed sql/schema/*.sql sql/sqlite/schema/*.sql
+var embeddedSchema embed.FS
+
+// schemaFS holds the migrations in sql/schema, compiled into the binary.
+var schemaFS, _ = fs.Sub(embeddedSchema, "sql/schema")
+
+// sqliteSchemaFS holds the SQLite migrations in sql/sqlite/schema.
+var sqliteSchemaFS, _ = fs.Sub(embeddedSchema, "sql/sqlite/schema")
+
+// runMigrate implements chirpy migrate up|down|status.
+func runMigrate(ctx context.Context, m *migrations.Migrator, direction string) error {
+    switch direction {
+    case "up":
+        results, err := m.Up(ctx)
+        for _, result := range results {
+            fmt.Println(result)
+        }
+        if err != nil {
+            return err
+        }
+        if len(results) == 0 {
+            fmt.Println("no migrations to apply")
+        }
+        return nil
+    case "down":
+        result, err := m.Down(ctx)
+        if err != nil {
+            return err
+        }
+        fmt.Println(result)
+        return nil
+    case "status":
+        statuses, err := m.Status(ctx)
+        if err != nil {
+            return err
+        }
+        for _, s := range statuses {
+            applied := "pending"
+            if !s.AppliedAt.IsZero() {
+                applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Printf("%-20s %s\n", applied, filepath.Base(s.Source.Path))
+        }
+        current, err := m.Version(ctx)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("database version %d, latest migration %d\n", current, m.Latest())
+        return nil
+    default:
+        return errors.New(usage)
+    }
+}
+
+// prepareSchema brings the database up to date when autoMigrate is set and
+// then makes sure the schema matches this build. It refuses to go on when
+// the database was migrated by a newer release, since this build may not
+// know how to use the new schema. Pending migrations only log a warning so
+// the server can still start while an operator runs them.
+func (cfg *APIConfig) prepareSchema(ctx context.Context, autoMigrate bool) error {
+    if autoMigrate {
+        results, err := cfg.Migrations.Up(ctx)
+        for _, result := range results {
+            cfg.Logger.Info("migration applied",
+                "migration", filepath.Base(result.Source.Path),
+                "duration", result.Duration,
+            )
+        }
+        if err != nil {
+            return fmt.Errorf("auto-migrate: %w", err)
+        }
+    }
+
+    version, err := cfg.Migrations.Check(ctx)
+    if errors.Is(err, migrations.ErrPending) {
+        cfg.Logger.Warn("database schema is out of date; run chirpy migrate up",
+            "version", version,
+            "expected", cfg.Migrations.Latest(),
+        )
+        return nil
+    }
+    return err
+}
@@ -2,16 +2,31 @@ package main
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
-	"strings"
     "time"
     "github.com/KrishKoria/Chirpy/internal/auth"
     "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/replica"
     "database/sql"
+    "github.com/google/uuid"
 )
 
 func respondWithError(w http.ResponseWriter, code int, msg string) {
-    respondWithJSON(w, code, map[string]string{"error": msg})
+    response := map[string]string{"error": msg}
+    if requestID := w.Header().Get(requestIDHeader); requestID != "" {
+        response["request_id"] = requestID
+    }
+    respondWithJSON(w, code, response)
+}
+
+// respondWithInternalError answers 500 with msg and makes sure err, which
+// the client never sees, ends up in the request log.
+func respondWithInternalError(w http.ResponseWriter, msg string, err error) {
+    if !recordError(w, err) {
+        slog.Error(msg, "error", err)
+    }
+    respondWithError(w, http.StatusInternalServerError, msg)
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -21,22 +36,50 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
     w.Write(resp)
 }
 
-func cleanProfanity(content string) string {
-    profaneWords := []string{"kerfuffle", "sharbert", "fornax"}
-    words := strings.Fields(content)
+// authenticate validates the bearer token and checks the account is not
+// suspended, writing the error response itself when either check fails.
+func (cfg *APIConfig) authenticate(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+    tokenString, err := auth.GetBearerToken(r.Header)
+    if err != nil {
+        respondWithError(w, http.StatusUnauthorized, "Authentication required")
+        return uuid.Nil, false
+    }
 
-    for i, word := range words {
-        for _, profane := range profaneWords {
-            if strings.ToLower(word) == profane {
-                words[i] = "****"
-                break
-            }
+    userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
+    if err != nil {
+        respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+        return uuid.Nil, false
+    }
+
+    user, err := cfg.Store.GetUserByID(r.Context(), userID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+            return uuid.Nil, false
         }
+        respondWithInternalError(w, "Failed to retrieve user", err)
+        return uuid.Nil, false
+    }
+
+    if userSuspended(user) {
+        respondWithError(w, http.StatusForbidden, "Account suspended")
+        return uuid.Nil, false
     }
 
-    return strings.Join(words, " ")
+    setRequestUser(r.Context(), userID)
+    replica.SetSessionUser(r.Context(), userID)
+    return userID, true
 }
 
+// authenticateOptional is authenticate for read endpoints where signing in is
+// optional. Anonymous requests yield uuid.Nil, which never matches a real
+// user and so only sees public content.
+func (cfg *APIConfig) authenticateOptional(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+    if r.Header.Get("Authorization") == "" {
+        return uuid.Nil, true
+    }
+    return cfg.authenticate(w, r)
+}
 
 func (cfg *APIConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
     refreshToken, err := auth.GetBearerToken(r.Header)
@@ -45,7 +88,7 @@ func (cfg *APIConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    tokenData, err := cfg.DB.GetRefreshToken(r.Context(), refreshToken)
+    tokenData, err := cfg.Store.GetRefreshToken(r.Context(), refreshToken)
     if err != nil {
         respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
         return
@@ -61,9 +104,20 @@ func (cfg *APIConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    newToken, err := auth.MakeJWT(tokenData.UserID, cfg.JWTSecret, time.Hour)
+    user, err := cfg.Store.GetUserByID(r.Context(), tokenData.UserID)
+    if err != nil {
+        respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+        return
+    }
+
+    if userSuspended(user) {
+        respondWithError(w, http.StatusForbidden, "Account suspended")
+        return
+    }
+
+    newToken, err := auth.MakeJWT(tokenData.UserID, cfg.JWTSecret, cfg.AccessTokenTTL)
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to generate access token")
+        respondWithInternalError(w, "Failed to generate access token", err)
         return
     }
 
@@ -88,14 +142,14 @@ func (cfg *APIConfig) revokeHandler(w http.ResponseWriter, r *http.Request) {
 
     now := time.Now().UTC()
 
-    err = cfg.DB.RevokeRefreshToken(r.Context(), database.RevokeRefreshTokenParams{
+    err = cfg.Store.RevokeRefreshToken(r.Context(), database.RevokeRefreshTokenParams{
         Token:     refreshToken,
         RevokedAt: sql.NullTime{Time: now, Valid: true},
         UpdatedAt: now,
     })
 
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to revoke token")
+        respondWithInternalError(w, "Failed to revoke token", err)
         return
     }
 
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/KrishKoria/Chirpy/internal/moderation"
+)
+
+// reloadProfanityFilter swaps the in-memory word list for the one currently
+// stored in the database.
+func (cfg *APIConfig) reloadProfanityFilter(ctx context.Context) error {
+    words, err := cfg.DB.ListProfanityWords(ctx)
+    if err != nil {
+        return err
+    }
+
+    rules := make([]moderation.Rule, 0, len(words))
+    for _, word := range words {
+        rules = append(rules, moderation.Rule{Word: word.Word, Action: moderation.Action(word.Action)})
+    }
+
+    cfg.Profanity.Load(rules)
+    return nil
+}
+
+// refreshSettings periodically reloads the word list, spam policy and plan
+// limits so edits made through another server instance are picked up. It runs
+// until ctx is cancelled.
+func (cfg *APIConfig) refreshSettings(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := cfg.reloadProfanityFilter(ctx); err != nil {
+                cfg.Logger.Error("reload profanity filter", "error", err)
+            }
+            if err := cfg.reloadSpamPolicy(ctx); err != nil {
+                cfg.Logger.Error("reload spam policy", "error", err)
+            }
+            if err := cfg.reloadEntitlements(ctx); err != nil {
+                cfg.Logger.Error("reload entitlements", "error", err)
+            }
+        }
+    }
+}
+
+func (cfg *APIConfig) listProfanityWordsHandler(w http.ResponseWriter, r *http.Request) {
+    words, err := cfg.DB.ListProfanityWords(r.Context())
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve word list", err)
+        return
+    }
+
+    type wordResponse struct {
+        Word      string    `json:"word"`
+        Action    string    `json:"action"`
+        UpdatedAt time.Time `json:"updated_at"`
+    }
+
+    response := []wordResponse{}
+    for _, word := range words {
+        response = append(response, wordResponse{
+            Word:      word.Word,
+            Action:    word.Action,
+            UpdatedAt: word.UpdatedAt,
+        })
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) upsertProfanityWordHandler(w http.ResponseWriter, r *http.Request) {
+    type wordRequest struct {
+        Action string `json:"action"`
+    }
+
+    var req wordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    if req.Action == "" {
+        req.Action = string(moderation.ActionMask)
+    }
+    if !moderation.Action(req.Action).Valid() {
+        respondWithError(w, http.StatusBadRequest, "Action must be mask, reject or flag")
+        return
+    }
+
+    word := strings.ToLower(strings.TrimSpace(r.PathValue("word")))
+    if word == "" || len(strings.Fields(word)) != 1 {
+        respondWithError(w, http.StatusBadRequest, "Word must be a single token")
+        return
+    }
+
+    saved, err := cfg.DB.UpsertProfanityWord(r.Context(), database.UpsertProfanityWordParams{
+        Word:   word,
+        Action: req.Action,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to save word", err)
+        return
+    }
+
+    if err := cfg.reloadProfanityFilter(r.Context()); err != nil {
+        respondWithInternalError(w, "Word saved but filter reload failed", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, map[string]string{
+        "word":   saved.Word,
+        "action": saved.Action,
+    })
+}
+
+func (cfg *APIConfig) deleteProfanityWordHandler(w http.ResponseWriter, r *http.Request) {
+    deleted, err := cfg.DB.DeleteProfanityWord(r.Context(), strings.ToLower(r.PathValue("word")))
+    if err != nil {
+        respondWithInternalError(w, "Failed to delete word", err)
+        return
+    }
+
+    if deleted == 0 {
+        respondWithError(w, http.StatusNotFound, "Word not found")
+        return
+    }
+
+    if err := cfg.reloadProfanityFilter(r.Context()); err != nil {
+        respondWithInternalError(w, "Word deleted but filter reload failed", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
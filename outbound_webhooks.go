@@ -0,0 +1,239 @@
+package main
+
+import (
+    "database/sql"
+    "encoding/json"
+    "net/http"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/outbound"
+    "github.com/google/uuid"
+)
+
+type webhookEndpointResponse struct {
+    ID        uuid.UUID `json:"id"`
+    URL       string    `json:"url"`
+    Events    []string  `json:"events"`
+    Secret    string    `json:"secret,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+func mapWebhookEndpoint(endpoint database.WebhookEndpoint) webhookEndpointResponse {
+    return webhookEndpointResponse{
+        ID:        endpoint.ID,
+        URL:       endpoint.Url,
+        Events:    endpoint.Events,
+        CreatedAt: endpoint.CreatedAt,
+    }
+}
+
+type webhookDeliveryResponse struct {
+    ID             uuid.UUID  `json:"id"`
+    EventID        uuid.UUID  `json:"event_id"`
+    EventType      string     `json:"event_type"`
+    Status         string     `json:"status"`
+    Attempts       int32      `json:"attempts"`
+    LastStatusCode *int32     `json:"last_status_code,omitempty"`
+    LastError      string     `json:"last_error,omitempty"`
+    NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+    CreatedAt      time.Time  `json:"created_at"`
+    DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Endpoint owners: user endpoints belong to the authenticated user, while
+// endpoints created by admins have no owner and receive every user's events.
+
+func (cfg *APIConfig) createMyWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+    cfg.createWebhookEndpoint(w, r, uuid.NullUUID{UUID: userID, Valid: true})
+}
+
+func (cfg *APIConfig) listMyWebhookEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+    cfg.listWebhookEndpoints(w, r, uuid.NullUUID{UUID: userID, Valid: true})
+}
+
+func (cfg *APIConfig) deleteMyWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+    cfg.deleteWebhookEndpoint(w, r, uuid.NullUUID{UUID: userID, Valid: true})
+}
+
+func (cfg *APIConfig) listMyWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+    cfg.listWebhookDeliveries(w, r, uuid.NullUUID{UUID: userID, Valid: true})
+}
+
+func (cfg *APIConfig) createGlobalWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+    cfg.createWebhookEndpoint(w, r, uuid.NullUUID{})
+}
+
+func (cfg *APIConfig) listGlobalWebhookEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+    cfg.listWebhookEndpoints(w, r, uuid.NullUUID{})
+}
+
+func (cfg *APIConfig) deleteGlobalWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+    cfg.deleteWebhookEndpoint(w, r, uuid.NullUUID{})
+}
+
+func (cfg *APIConfig) listGlobalWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+    cfg.listWebhookDeliveries(w, r, uuid.NullUUID{})
+}
+
+func (cfg *APIConfig) createWebhookEndpoint(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) {
+    type endpointRequest struct {
+        URL    string   `json:"url"`
+        Events []string `json:"events"`
+    }
+
+    var req endpointRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    // User endpoints must use https; admins may point global ones at plain
+    // http. Neither may target the server's own network.
+    parsed, err := outbound.CheckURL(r.Context(), req.URL, owner.Valid)
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    if len(req.Events) == 0 {
+        respondWithError(w, http.StatusBadRequest, "At least one event is required")
+        return
+    }
+    for _, event := range req.Events {
+        if !outboundEventTypes[event] {
+            respondWithError(w, http.StatusBadRequest, "Unknown event: "+event)
+            return
+        }
+    }
+
+    // The secret is only ever shown in this response.
+    secret, err := auth.MakeRefreshToken()
+    if err != nil {
+        respondWithInternalError(w, "Failed to generate secret", err)
+        return
+    }
+
+    endpoint, err := cfg.DB.CreateWebhookEndpoint(r.Context(), database.CreateWebhookEndpointParams{
+        UserID: owner,
+        Url:    parsed.String(),
+        Secret: secret,
+        Events: req.Events,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to create webhook endpoint", err)
+        return
+    }
+
+    response := mapWebhookEndpoint(endpoint)
+    response.Secret = endpoint.Secret
+    respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (cfg *APIConfig) listWebhookEndpoints(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) {
+    endpoints, err := cfg.DB.ListWebhookEndpoints(r.Context(), owner)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve webhook endpoints", err)
+        return
+    }
+
+    response := []webhookEndpointResponse{}
+    for _, endpoint := range endpoints {
+        response = append(response, mapWebhookEndpoint(endpoint))
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) deleteWebhookEndpoint(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) {
+    endpointID, err := uuid.Parse(r.PathValue("endpointID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid endpoint ID")
+        return
+    }
+
+    deleted, err := cfg.DB.DeleteWebhookEndpoint(r.Context(), database.DeleteWebhookEndpointParams{
+        ID:     endpointID,
+        UserID: owner,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to delete webhook endpoint", err)
+        return
+    }
+
+    if deleted == 0 {
+        respondWithError(w, http.StatusNotFound, "Webhook endpoint not found")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) listWebhookDeliveries(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) {
+    endpointID, err := uuid.Parse(r.PathValue("endpointID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid endpoint ID")
+        return
+    }
+
+    _, err = cfg.DB.GetWebhookEndpoint(r.Context(), database.GetWebhookEndpointParams{
+        ID:     endpointID,
+        UserID: owner,
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Webhook endpoint not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve webhook endpoint", err)
+        return
+    }
+
+    deliveries, err := cfg.DB.ListDeliveriesByEndpoint(r.Context(), endpointID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve deliveries", err)
+        return
+    }
+
+    response := []webhookDeliveryResponse{}
+    for _, delivery := range deliveries {
+        item := webhookDeliveryResponse{
+            ID:        delivery.ID,
+            EventID:   delivery.EventID,
+            EventType: delivery.EventType,
+            Status:    delivery.Status,
+            Attempts:  delivery.Attempts,
+            LastError: delivery.LastError.String,
+            CreatedAt: delivery.CreatedAt,
+        }
+        if delivery.LastStatusCode.Valid {
+            item.LastStatusCode = &delivery.LastStatusCode.Int32
+        }
+        if delivery.Status == deliveryStatusPending {
+            item.NextAttemptAt = &delivery.NextAttemptAt
+        }
+        if delivery.DeliveredAt.Valid {
+            item.DeliveredAt = &delivery.DeliveredAt.Time
+        }
+        response = append(response, item)
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
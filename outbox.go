@@ -0,0 +1,162 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/KrishKoria/Chirpy/internal/outbound"
+    "github.com/KrishKoria/Chirpy/internal/tracing"
+    "github.com/google/uuid"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Outbound event types. The follow, mention and subscription-expiry queries
+// write their events in SQL and use the same names.
+const (
+    eventChirpCreated        = "chirp.created"
+    eventChirpDeleted        = "chirp.deleted"
+    eventFollowerNew         = "follower.new"
+    eventMention             = "mention"
+    eventSubscriptionChanged = "subscription.changed"
+)
+
+var outboundEventTypes = map[string]bool{
+    eventChirpCreated:        true,
+    eventChirpDeleted:        true,
+    eventFollowerNew:         true,
+    eventMention:             true,
+    eventSubscriptionChanged: true,
+}
+
+const (
+    deliveryStatusPending   = "pending"
+    deliveryStatusSucceeded = "succeeded"
+    deliveryStatusDead      = "dead"
+
+    // deliveryLease is how long a claimed delivery is hidden from other
+    // workers. If a worker dies mid-send, the delivery is retried after it.
+    deliveryLease     = 5 * time.Minute
+    deliveryBatchSize = 20
+)
+
+// inTx runs fn inside a database transaction, committing if it returns nil.
+func (cfg *APIConfig) inTx(ctx context.Context, fn func(q *database.Queries) error) error {
+    tx, err := cfg.Conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := fn(database.New(tracing.WrapDB(tx, semconv.DBSystemPostgreSQL))); err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+    // Transactions run on the primary directly, past the replica router.
+    if cfg.Replicas != nil {
+        cfg.Replicas.Wrote(ctx)
+    }
+    return nil
+}
+
+// eventQueue is where enqueueEvent writes: the queries of a transaction, or
+// a chirpTx.
+type eventQueue interface {
+    EnqueueOutboxEvent(ctx context.Context, arg database.EnqueueOutboxEventParams) error
+}
+
+// enqueueEvent writes an outbound event for the endpoints of userID, and for
+// every global endpoint. Call it with the transaction making the change so
+// the event is only sent if the change commits.
+func enqueueEvent(ctx context.Context, q eventQueue, eventType string, userID uuid.UUID, payload any) error {
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+
+    return q.EnqueueOutboxEvent(ctx, database.EnqueueOutboxEventParams{
+        EventType: eventType,
+        UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+        Payload:   data,
+    })
+}
+
+// deliverWebhooks fans new outbox events out to matching endpoints and sends
+// deliveries that are due. It runs until ctx is cancelled.
+func (cfg *APIConfig) deliverWebhooks(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        now := time.Now().UTC()
+        if _, err := cfg.DB.DispatchOutboxEvents(ctx, now); err != nil {
+            cfg.Logger.Error("dispatch outbox events", "error", err)
+        }
+
+        ids, err := cfg.DB.ClaimDueDeliveries(ctx, database.ClaimDueDeliveriesParams{
+            LeaseUntil: now.Add(deliveryLease),
+            Now:        now,
+            BatchSize:  deliveryBatchSize,
+        })
+        if err != nil {
+            cfg.Logger.Error("claim webhook deliveries", "error", err)
+        }
+        for _, id := range ids {
+            if err := cfg.attemptDelivery(ctx, id); err != nil {
+                cfg.Logger.Error("record webhook delivery", "delivery_id", id, "error", err)
+            }
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// attemptDelivery sends one delivery and records the outcome, scheduling a
+// retry with backoff or dead-lettering it once it runs out of attempts.
+func (cfg *APIConfig) attemptDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+    target, err := cfg.DB.GetDeliveryTarget(ctx, deliveryID)
+    if err != nil {
+        return err
+    }
+
+    status, err := cfg.Outbound.Send(ctx, target.Url, target.Secret, outbound.Message{
+        DeliveryID: target.ID,
+        EventID:    target.EventID,
+        EventType:  target.EventType,
+        CreatedAt:  target.CreatedAt,
+        Payload:    target.Payload,
+    })
+    statusCode := sql.NullInt32{Int32: int32(status), Valid: status != 0}
+
+    if err == nil {
+        cfg.Metrics.Webhooks.WithLabelValues(metrics.WebhookOutbound, metrics.WebhookProcessed).Inc()
+        return cfg.DB.MarkDeliverySucceeded(ctx, database.MarkDeliverySucceededParams{
+            ID:             target.ID,
+            LastStatusCode: statusCode,
+        })
+    }
+
+    attempts := int(target.Attempts) + 1
+    next, outcome := deliveryStatusPending, metrics.WebhookRetried
+    if attempts >= outbound.MaxAttempts {
+        next, outcome = deliveryStatusDead, metrics.WebhookDead
+    }
+    cfg.Metrics.Webhooks.WithLabelValues(metrics.WebhookOutbound, outcome).Inc()
+
+    return cfg.DB.MarkDeliveryFailed(ctx, database.MarkDeliveryFailedParams{
+        ID:             target.ID,
+        Status:         next,
+        LastStatusCode: statusCode,
+        LastError:      sql.NullString{String: err.Error(), Valid: true},
+        NextAttemptAt:  time.Now().UTC().Add(outbound.Backoff(attempts)),
+    })
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+    reportStatusOpen      = "open"
+    reportStatusResolved  = "resolved"
+    reportStatusDismissed = "dismissed"
+
+    moderationHideChirp   = "hide_chirp"
+    moderationSuspendUser = "suspend_user"
+    moderationDismiss     = "dismiss"
+)
+
+// errReportClosed is returned from the resolving transaction when another
+// moderator closed the report first.
+var errReportClosed = errors.New("report already closed")
+
+var reportCategories = map[string]bool{
+    "spam":       true,
+    "harassment": true,
+    "hate":       true,
+    "violence":   true,
+    "sexual":     true,
+    "self_harm":  true,
+    "profanity":  true,
+    "other":      true,
+}
+
+type reportResponse struct {
+    ID            uuid.UUID  `json:"id"`
+    ReporterID    *uuid.UUID `json:"reporter_id"`
+    TargetUserID  uuid.UUID  `json:"target_user_id"`
+    TargetChirpID *uuid.UUID `json:"target_chirp_id,omitempty"`
+    Category      string     `json:"category"`
+    Details       string     `json:"details"`
+    Status        string     `json:"status"`
+    ResolvedBy    *uuid.UUID `json:"resolved_by,omitempty"`
+    ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+    CreatedAt     time.Time  `json:"created_at"`
+}
+
+func mapReport(report database.Report) reportResponse {
+    response := reportResponse{
+        ID:           report.ID,
+        TargetUserID: report.TargetUserID,
+        Category:     report.Category,
+        Details:      report.Details,
+        Status:       report.Status,
+        CreatedAt:    report.CreatedAt,
+    }
+    if report.ReporterID.Valid {
+        response.ReporterID = &report.ReporterID.UUID
+    }
+    if report.TargetChirpID.Valid {
+        response.TargetChirpID = &report.TargetChirpID.UUID
+    }
+    if report.ResolvedBy.Valid {
+        response.ResolvedBy = &report.ResolvedBy.UUID
+    }
+    if report.ResolvedAt.Valid {
+        response.ResolvedAt = &report.ResolvedAt.Time
+    }
+    return response
+}
+
+func (cfg *APIConfig) createReportHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    type reportRequest struct {
+        ChirpID  string `json:"chirp_id"`
+        UserID   string `json:"user_id"`
+        Category string `json:"category"`
+        Details  string `json:"details"`
+    }
+
+    var req reportRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    if (req.ChirpID == "") == (req.UserID == "") {
+        respondWithError(w, http.StatusBadRequest, "Exactly one of chirp_id or user_id is required")
+        return
+    }
+
+    if !reportCategories[req.Category] {
+        respondWithError(w, http.StatusBadRequest, "Invalid report category")
+        return
+    }
+
+    if len(req.Details) > 1000 {
+        respondWithError(w, http.StatusBadRequest, "Details are too long")
+        return
+    }
+
+    params := database.CreateReportParams{
+        ReporterID: uuid.NullUUID{UUID: userID, Valid: true},
+        Category:   req.Category,
+        Details:    strings.TrimSpace(req.Details),
+    }
+
+    if req.ChirpID != "" {
+        chirpID, err := uuid.Parse(req.ChirpID)
+        if err != nil {
+            respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+            return
+        }
+
+        chirp, err := cfg.Store.GetChirpByID(r.Context(), database.GetChirpByIDParams{
+            ID:       chirpID,
+            ViewerID: userID,
+            Now:      time.Now().UTC(),
+        })
+        if err != nil {
+            if err == sql.ErrNoRows {
+                respondWithError(w, http.StatusNotFound, "Chirp not found")
+                return
+            }
+            respondWithInternalError(w, "Failed to retrieve chirp", err)
+            return
+        }
+
+        params.TargetChirpID = uuid.NullUUID{UUID: chirp.ID, Valid: true}
+        params.TargetUserID = chirp.UserID
+    } else {
+        targetID, err := uuid.Parse(req.UserID)
+        if err != nil {
+            respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+            return
+        }
+
+        target, err := cfg.Store.GetUserByID(r.Context(), targetID)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                respondWithError(w, http.StatusNotFound, "User not found")
+                return
+            }
+            respondWithInternalError(w, "Failed to retrieve user", err)
+            return
+        }
+
+        params.TargetUserID = target.ID
+    }
+
+    if params.TargetUserID == userID {
+        respondWithError(w, http.StatusBadRequest, "You cannot report yourself")
+        return
+    }
+
+    report, err := cfg.DB.CreateReport(r.Context(), params)
+    if err != nil {
+        respondWithInternalError(w, "Failed to create report", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusCreated, mapReport(report))
+}
+
+func (cfg *APIConfig) listReportsHandler(w http.ResponseWriter, r *http.Request) {
+    status := r.URL.Query().Get("status")
+    if status == "" {
+        status = reportStatusOpen
+    }
+    if status != reportStatusOpen && status != reportStatusResolved && status != reportStatusDismissed {
+        respondWithError(w, http.StatusBadRequest, "Invalid report status")
+        return
+    }
+
+    reports, err := cfg.DB.ListReportsByStatus(r.Context(), status)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve reports", err)
+        return
+    }
+
+    response := []reportResponse{}
+    for _, report := range reports {
+        response = append(response, mapReport(report))
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) updateReportHandler(w http.ResponseWriter, r *http.Request) {
+    moderatorID := userIDFromContext(r.Context())
+
+    reportID, err := uuid.Parse(r.PathValue("reportID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid report ID")
+        return
+    }
+
+    type actionRequest struct {
+        Action string `json:"action"`
+        Reason string `json:"reason"`
+    }
+
+    var req actionRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    report, err := cfg.DB.GetReportByID(r.Context(), reportID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Report not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve report", err)
+        return
+    }
+
+    if report.Status != reportStatusOpen {
+        respondWithError(w, http.StatusConflict, "Report is already closed")
+        return
+    }
+
+    status := reportStatusResolved
+    switch req.Action {
+    case moderationHideChirp:
+        if !report.TargetChirpID.Valid {
+            respondWithError(w, http.StatusBadRequest, "Report does not target a chirp")
+            return
+        }
+    case moderationSuspendUser:
+    case moderationDismiss:
+        status = reportStatusDismissed
+    default:
+        respondWithError(w, http.StatusBadRequest, "Action must be hide_chirp, suspend_user or dismiss")
+        return
+    }
+
+    // Closing the report comes first: it only matches an open report and
+    // holds its row until commit, so two moderators acting on the same
+    // report cannot both apply a sanction.
+    var resolved database.Report
+    err = cfg.inTx(r.Context(), func(q *database.Queries) error {
+        var err error
+        resolved, err = q.ResolveReport(r.Context(), database.ResolveReportParams{
+            ID:         report.ID,
+            Status:     status,
+            ResolvedBy: uuid.NullUUID{UUID: moderatorID, Valid: true},
+        })
+        if err == sql.ErrNoRows {
+            return errReportClosed
+        }
+        if err != nil {
+            return err
+        }
+
+        switch req.Action {
+        case moderationHideChirp:
+            err = q.HideChirp(r.Context(), report.TargetChirpID.UUID)
+        case moderationSuspendUser:
+            err = suspendUser(r.Context(), q, report.TargetUserID, sql.NullTime{})
+        }
+        if err != nil {
+            return err
+        }
+
+        return q.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+            ModeratorID:   uuid.NullUUID{UUID: moderatorID, Valid: true},
+            Action:        req.Action,
+            ReportID:      uuid.NullUUID{UUID: report.ID, Valid: true},
+            TargetUserID:  uuid.NullUUID{UUID: report.TargetUserID, Valid: true},
+            TargetChirpID: report.TargetChirpID,
+            Reason:        strings.TrimSpace(req.Reason),
+        })
+    })
+    if err != nil {
+        if err == errReportClosed {
+            respondWithError(w, http.StatusConflict, "Report is already closed")
+            return
+        }
+        respondWithInternalError(w, "Failed to update report", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, mapReport(resolved))
+}
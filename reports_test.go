@@ -0,0 +1,97 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/store"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// Reports are checked against what the reporter can see before the report
+// is written, so every rejection here runs without a database.
+func TestCreateReportValidation(t *testing.T) {
+    ctx := context.Background()
+    cfg := &APIConfig{Store: store.NewMemory(), JWTSecret: "secret"}
+    alice, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: "alice@example.com", HashedPassword: "hash"})
+    require.NoError(t, err)
+    bob, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: "bob@example.com", HashedPassword: "hash"})
+    require.NoError(t, err)
+
+    chirp := func(userID uuid.UUID, visibility string) string {
+        now := time.Now()
+        created, err := cfg.Store.CreateChirp(ctx, database.CreateChirpParams{
+            ID:         uuid.New(),
+            CreatedAt:  now,
+            UpdatedAt:  now,
+            Body:       "hello",
+            UserID:     userID,
+            Visibility: visibility,
+        })
+        require.NoError(t, err)
+        return created.ID.String()
+    }
+    ownChirp := chirp(bob.ID, visibilityPublic)
+    hiddenChirp := chirp(alice.ID, visibilityFollowers)
+    token, err := auth.MakeJWT(bob.ID, cfg.JWTSecret, time.Hour)
+    require.NoError(t, err)
+
+    tests := []struct {
+        name string
+        body string
+        want int
+    }{
+        {"no target", `{"category": "spam"}`, http.StatusBadRequest},
+        {"both targets", `{"chirp_id": "` + ownChirp + `", "user_id": "` + alice.ID.String() + `", "category": "spam"}`, http.StatusBadRequest},
+        {"unknown category", `{"user_id": "` + alice.ID.String() + `", "category": "rude"}`, http.StatusBadRequest},
+        {"long details", `{"user_id": "` + alice.ID.String() + `", "category": "spam", "details": "` + strings.Repeat("x", 1001) + `"}`, http.StatusBadRequest},
+        {"own chirp", `{"chirp_id": "` + ownChirp + `", "category": "spam"}`, http.StatusBadRequest},
+        {"self", `{"user_id": "` + bob.ID.String() + `", "category": "spam"}`, http.StatusBadRequest},
+        {"unknown user", `{"user_id": "` + uuid.NewString() + `", "category": "spam"}`, http.StatusNotFound},
+        {"chirp the reporter cannot see", `{"chirp_id": "` + hiddenChirp + `", "category": "spam"}`, http.StatusNotFound},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodPost, "/api/reports", strings.NewReader(tt.body))
+            req.Header.Set("Authorization", "Bearer "+token)
+            rec := httptest.NewRecorder()
+            cfg.createReportHandler(rec, req)
+            assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+        })
+    }
+}
+
+func TestReportQueueRequiresModerator(t *testing.T) {
+    ctx := context.Background()
+    cfg := &APIConfig{Store: store.NewMemory(), JWTSecret: "secret"}
+    queue := cfg.middlewareRequireRole(auth.RoleModerator, http.HandlerFunc(cfg.listReportsHandler))
+
+    status := func(role string) int {
+        user, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: role + "@example.com", HashedPassword: "hash"})
+        require.NoError(t, err)
+        _, err = cfg.Store.SetUserRole(ctx, database.SetUserRoleParams{ID: user.ID, Role: role})
+        require.NoError(t, err)
+        token, err := auth.MakeJWT(user.ID, cfg.JWTSecret, time.Hour)
+        require.NoError(t, err)
+
+        // An unknown status is rejected before the queue is read.
+        req := httptest.NewRequest(http.MethodGet, "/api/admin/reports?status=pending", nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        rec := httptest.NewRecorder()
+        queue.ServeHTTP(rec, req)
+        return rec.Code
+    }
+
+    assert.Equal(t, http.StatusForbidden, status(auth.RoleUser))
+    assert.Equal(t, http.StatusBadRequest, status(auth.RoleModerator))
+    assert.Equal(t, http.StatusBadRequest, status(auth.RoleAdmin))
+}
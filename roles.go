@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/KrishKoria/Chirpy/internal/auth"
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/KrishKoria/Chirpy/internal/replica"
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "userID"
+
+// middlewareRequireRole authenticates the request and only passes it on when
+// the caller's role, read fresh from the database so demotions apply at once,
+// meets role. The caller's ID is available to next via userIDFromContext.
+func (cfg *APIConfig) middlewareRequireRole(role string, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        tokenString, err := auth.GetBearerToken(r.Header)
+        if err != nil {
+            respondWithError(w, http.StatusUnauthorized, "Authentication required")
+            return
+        }
+
+        userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
+        if err != nil {
+            respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+            return
+        }
+
+        user, err := cfg.Store.GetUserByID(r.Context(), userID)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+                return
+            }
+            respondWithInternalError(w, "Failed to retrieve user", err)
+            return
+        }
+
+        if userSuspended(user) {
+            respondWithError(w, http.StatusForbidden, "Account suspended")
+            return
+        }
+
+        if !auth.RoleAllows(user.Role, role) {
+            respondWithError(w, http.StatusForbidden, "Insufficient permissions")
+            return
+        }
+
+        setRequestUser(r.Context(), user.ID)
+        replica.SetSessionUser(r.Context(), user.ID)
+        ctx := context.WithValue(r.Context(), userIDContextKey, user.ID)
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+func userIDFromContext(ctx context.Context) uuid.UUID {
+    userID, _ := ctx.Value(userIDContextKey).(uuid.UUID)
+    return userID
+}
+
+func (cfg *APIConfig) setUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+    targetID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    type roleRequest struct {
+        Role string `json:"role"`
+    }
+
+    var req roleRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    if !auth.ValidRole(req.Role) {
+        respondWithError(w, http.StatusBadRequest, "Role must be user, moderator or admin")
+        return
+    }
+
+    if targetID == userIDFromContext(r.Context()) && req.Role != auth.RoleAdmin {
+        respondWithError(w, http.StatusBadRequest, "You cannot remove your own admin role")
+        return
+    }
+
+    user, err := cfg.Store.SetUserRole(r.Context(), database.SetUserRoleParams{
+        ID:   targetID,
+        Role: req.Role,
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to update role", err)
+        return
+    }
+
+    type roleResponse struct {
+        ID    uuid.UUID `json:"id"`
+        Email string    `json:"email"`
+        Role  string    `json:"role"`
+    }
+
+    respondWithJSON(w, http.StatusOK, roleResponse{
+        ID:    user.ID,
+        Email: user.Email,
+        Role:  user.Role,
+    })
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/replica"
+    "github.com/KrishKoria/Chirpy/internal/tracing"
+)
+
+// middlewareMaxBody caps request bodies at limit bytes. Handlers see a read
+// error once the limit is passed and answer 400 as for any bad payload.
+func middlewareMaxBody(limit int64, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        r.Body = http.MaxBytesReader(w, r.Body, limit)
+        next.ServeHTTP(w, r)
+    })
+}
+
+// handler wraps mux in the middleware every request goes through. Metrics and
+// span naming read the route the mux matched, so they sit directly around
+// it; see tracing.Route. The access log reads it too, so nothing between the
+// log and the mux may replace the request.
+func (cfg *APIConfig) handler(mux http.Handler, maxBodyBytes int64) http.Handler {
+    inner := cfg.Metrics.Middleware(middlewareMaxBody(maxBodyBytes, tracing.Route(mux)))
+    return tracing.Middleware(replica.Middleware(cfg.middlewareRequestLog(inner)))
+}
+
+// After the requests have drained, stopping the workers and flushing traces
+// each get their own deadline, so a drain that uses up SHUTDOWN_TIMEOUT
+// still leaves them time to finish before the database is closed.
+const (
+    workerStopTimeout = 10 * time.Second
+    traceFlushTimeout = 5 * time.Second
+)
+
+// worker is a background loop that can be stopped and waited for.
+type worker struct {
+    name      string
+    startedAt time.Time
+    cancel    context.CancelFunc
+    done      chan struct{}
+}
+
+func startWorker(name string, run func(ctx context.Context)) *worker {
+    ctx, cancel := context.WithCancel(context.Background())
+    w := &worker{name: name, startedAt: time.Now(), cancel: cancel, done: make(chan struct{})}
+    go func() {
+        defer close(w.done)
+        run(ctx)
+    }()
+    return w
+}
+
+// running reports whether the worker's loop has not yet returned.
+func (w *worker) running() bool {
+    select {
+    case <-w.done:
+        return false
+    default:
+        return true
+    }
+}
+
+// stopWorkers stops workers one at a time, in order, so later workers can
+// finish what earlier ones produced. It gives up waiting when ctx is done.
+func (cfg *APIConfig) stopWorkers(ctx context.Context, workers []*worker) {
+    for _, w := range workers {
+        w.cancel()
+        select {
+        case <-w.done:
+            cfg.Logger.Info("worker stopped", "worker", w.name)
+        case <-ctx.Done():
+            cfg.Logger.Warn("worker did not stop in time", "worker", w.name)
+        }
+    }
+}
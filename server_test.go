@@ -0,0 +1,128 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/stretchr/testify/assert"
+    "go.opentelemetry.io/otel"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// The production chain swaps the request for a copy between the outer
+// middleware and the mux; the route must still reach the span.
+func TestHandlerNamesSpanAfterRoute(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    previous := otel.GetTracerProvider()
+    otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+    t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+    cfg := &APIConfig{
+        Logger:  slog.New(slog.NewJSONHandler(io.Discard, nil)),
+        Metrics: metrics.New(),
+    }
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {})
+
+    req := httptest.NewRequest(http.MethodGet, "/api/chirps/123", nil)
+    cfg.handler(mux, 1<<20).ServeHTTP(httptest.NewRecorder(), req)
+
+    spans := recorder.Ended()
+    assert.Len(t, spans, 1)
+    assert.Equal(t, "GET /api/chirps/{chirpID}", spans[0].Name())
+    assert.Contains(t, spans[0].Attributes(), semconv.HTTPRoute("GET /api/chirps/{chirpID}"))
+}
+
+func TestHandlerLogsRoute(t *testing.T) {
+    var logs bytes.Buffer
+    cfg := &APIConfig{
+        Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+        Metrics: metrics.New(),
+    }
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /api/chirps/{chirpID}", func(w http.ResponseWriter, r *http.Request) {})
+
+    req := httptest.NewRequest(http.MethodGet, "/api/chirps/123", nil)
+    cfg.handler(mux, 1<<20).ServeHTTP(httptest.NewRecorder(), req)
+
+    var line map[string]any
+    assert.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+    assert.Equal(t, "GET /api/chirps/{chirpID}", line["route"])
+    assert.Equal(t, "/api/chirps/123", line["path"])
+}
+
+func TestHandlerLimitsBody(t *testing.T) {
+    cfg := &APIConfig{
+        Logger:  slog.New(slog.NewJSONHandler(io.Discard, nil)),
+        Metrics: metrics.New(),
+    }
+    var readErr error
+    mux := http.NewServeMux()
+    mux.HandleFunc("POST /api/chirps", func(w http.ResponseWriter, r *http.Request) {
+        _, readErr = io.ReadAll(r.Body)
+    })
+    handler := cfg.handler(mux, 16)
+
+    handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(strings.Repeat("x", 16))))
+    assert.NoError(t, readErr)
+
+    handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(strings.Repeat("x", 17))))
+    var tooLarge *http.MaxBytesError
+    assert.True(t, errors.As(readErr, &tooLarge))
+}
+
+func TestStopWorkersInOrder(t *testing.T) {
+    cfg := &APIConfig{Logger: slog.New(slog.NewJSONHandler(io.Discard, nil))}
+    var mu sync.Mutex
+    var stopped []string
+    run := func(name string) func(ctx context.Context) {
+        return func(ctx context.Context) {
+            <-ctx.Done()
+            mu.Lock()
+            defer mu.Unlock()
+            stopped = append(stopped, name)
+        }
+    }
+    workers := []*worker{startWorker("first", run("first")), startWorker("second", run("second"))}
+
+    cfg.stopWorkers(context.Background(), workers)
+
+    assert.Equal(t, []string{"first", "second"}, stopped)
+    for _, w := range workers {
+        assert.False(t, w.running())
+    }
+}
+
+// A worker that ignores cancellation must not hold up shutdown past the
+// deadline, nor keep the workers after it from being cancelled.
+func TestStopWorkersGivesUp(t *testing.T) {
+    cfg := &APIConfig{Logger: slog.New(slog.NewJSONHandler(io.Discard, nil))}
+    release := make(chan struct{})
+    defer close(release)
+    stuck := startWorker("stuck", func(ctx context.Context) { <-release })
+    next := startWorker("next", func(ctx context.Context) { <-ctx.Done() })
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    cfg.stopWorkers(ctx, []*worker{stuck, next})
+
+    assert.True(t, stuck.running())
+    select {
+    case <-next.done:
+    case <-time.After(time.Second):
+        t.Fatal("next worker was not cancelled")
+    }
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/KrishKoria/Chirpy/internal/spam"
+	"github.com/google/uuid"
+)
+
+const spamPolicySetting = "spam_policy"
+
+// reloadSpamPolicy loads the stored spam policy, falling back to the defaults
+// for anything that has never been configured.
+func (cfg *APIConfig) reloadSpamPolicy(ctx context.Context) error {
+    policy := spam.DefaultPolicy()
+
+    value, err := cfg.DB.GetSetting(ctx, spamPolicySetting)
+    if err != nil && err != sql.ErrNoRows {
+        return err
+    }
+    if err == nil {
+        if err := json.Unmarshal(value, &policy); err != nil {
+            return err
+        }
+        if err := policy.Validate(); err != nil {
+            return err
+        }
+    }
+
+    cfg.Spam.Load(policy)
+    return nil
+}
+
+// checkSpam evaluates a new chirp against the spam policy. Rejections are
+// written as a 429 and reported as handled; violations that only need review
+// are returned so the caller can queue the chirp once it exists.
+func (cfg *APIConfig) checkSpam(w http.ResponseWriter, r *http.Request, user database.User, body string) ([]spam.Violation, bool) {
+    policy := cfg.Spam.Policy()
+
+    // Soft-deleted chirps still count, so deleting posts does not reset a quota.
+    recent, err := cfg.Store.CountChirpsByAuthorSince(r.Context(), database.CountChirpsByAuthorSinceParams{
+        UserID: user.ID,
+        Since:  time.Now().Add(-policy.Window()),
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to check posting rate", err)
+        return nil, false
+    }
+
+    var duplicates int64
+    if policy.DuplicateWindowSeconds > 0 {
+        duplicates, err = cfg.Store.CountDuplicateChirpsSince(r.Context(), database.CountDuplicateChirpsSinceParams{
+            UserID: user.ID,
+            Body:   body,
+            Since:  time.Now().Add(-policy.DuplicateWindow()),
+        })
+        if err != nil {
+            respondWithInternalError(w, "Failed to check for duplicates", err)
+            return nil, false
+        }
+    }
+
+    violations := policy.Evaluate(spam.Post{
+        Body:        body,
+        RecentPosts: recent,
+        Duplicates:  duplicates,
+        AccountAge:  time.Since(user.CreatedAt),
+        IsChirpyRed: user.IsChirpyRed,
+    })
+
+    var queued []spam.Violation
+    for _, violation := range violations {
+        if violation.Action != spam.ActionReject {
+            queued = append(queued, violation)
+            continue
+        }
+
+        retryAfter := policy.Window()
+        if violation.Rule == spam.RuleDuplicate {
+            retryAfter = policy.DuplicateWindow()
+        }
+        w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+        respondWithJSON(w, http.StatusTooManyRequests, map[string]string{
+            "error": "Chirp rejected by spam protection",
+            "rule":  violation.Rule,
+        })
+        return nil, false
+    }
+
+    return queued, true
+}
+
+// queueSpamReport files a system report so moderators can review a chirp that
+// tripped a queue-only spam rule.
+func queueSpamReport(ctx context.Context, q chirpTx, chirp database.Chirp, violations []spam.Violation) error {
+    rules := make([]string, 0, len(violations))
+    for _, violation := range violations {
+        rules = append(rules, violation.Rule)
+    }
+
+    _, err := q.CreateReport(ctx, database.CreateReportParams{
+        TargetUserID:  chirp.UserID,
+        TargetChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+        Category:      "spam",
+        Details:       "automatic: " + strings.Join(rules, ", "),
+    })
+    return err
+}
+
+func (cfg *APIConfig) getSpamPolicyHandler(w http.ResponseWriter, r *http.Request) {
+    respondWithJSON(w, http.StatusOK, cfg.Spam.Policy())
+}
+
+func (cfg *APIConfig) updateSpamPolicyHandler(w http.ResponseWriter, r *http.Request) {
+    // Fields left out of the request keep their current values.
+    policy := cfg.Spam.Policy()
+    if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    if err := policy.Validate(); err != nil {
+        respondWithError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    value, err := json.Marshal(policy)
+    if err != nil {
+        respondWithInternalError(w, "Failed to encode policy", err)
+        return
+    }
+
+    err = cfg.DB.UpsertSetting(r.Context(), database.UpsertSettingParams{
+        Key:   spamPolicySetting,
+        Value: value,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to save policy", err)
+        return
+    }
+
+    cfg.Spam.Load(policy)
+    respondWithJSON(w, http.StatusOK, policy)
+}
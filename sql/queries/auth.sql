@@ -22,3 +22,8 @@ SELECT * FROM refresh_tokens WHERE token = $1 LIMIT 1;
 UPDATE refresh_tokens
 SET revoked_at = $2, updated_at = $3
 WHERE token = $1;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,33 @@
+-- name: CreateBlock :exec
+WITH removed_follows AS (
+    DELETE FROM follows
+    WHERE (follower_id = sqlc.arg(blocker_id)::uuid AND followee_id = sqlc.arg(blocked_id)::uuid)
+       OR (follower_id = sqlc.arg(blocked_id)::uuid AND followee_id = sqlc.arg(blocker_id)::uuid)
+), removed_requests AS (
+    DELETE FROM follow_requests
+    WHERE (requester_id = sqlc.arg(blocker_id)::uuid AND target_id = sqlc.arg(blocked_id)::uuid)
+       OR (requester_id = sqlc.arg(blocked_id)::uuid AND target_id = sqlc.arg(blocker_id)::uuid)
+)
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES (sqlc.arg(blocker_id)::uuid, sqlc.arg(blocked_id)::uuid, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :exec
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = sqlc.arg(user_a)::uuid AND blocked_id = sqlc.arg(user_b)::uuid)
+       OR (blocker_id = sqlc.arg(user_b)::uuid AND blocked_id = sqlc.arg(user_a)::uuid)
+);
+
+-- name: CreateMute :exec
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :exec
+DELETE FROM mutes
+WHERE muter_id = $1 AND muted_id = $2;
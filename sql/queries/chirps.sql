@@ -1,26 +1,200 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, body, user_id;
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, visibility)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at;
 
 
 -- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
+WHERE deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id)::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id)::uuid)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id)::uuid AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    user_id = sqlc.arg(viewer_id)::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility = 'public'
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > sqlc.arg(now)::timestamp)
+      )
+    )
+  )
 ORDER BY created_at ASC;
 
 -- name: GetChirpByID :one
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id)::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id)::uuid)
+  )
+  AND (
+    user_id = sqlc.arg(viewer_id)::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility IN ('public', 'unlisted')
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > sqlc.arg(now)::timestamp)
+      )
+    )
+  );
 
--- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1;
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = sqlc.arg(now)::timestamp, updated_at = sqlc.arg(now)::timestamp
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
 
 
 -- name: GetChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id)::uuid AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id)::uuid)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id)::uuid AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    user_id = sqlc.arg(viewer_id)::uuid
+    OR (
+      hidden_at IS NULL
+      AND (
+        visibility = 'public'
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        ))
+        OR (visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)::uuid
+        ))
+      )
+      AND (
+        NOT EXISTS (
+            SELECT 1 FROM users
+            WHERE users.id = chirps.user_id AND users.is_private
+        )
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = chirps.user_id
+          AND users.shadow_banned_at IS NOT NULL
+          AND (users.shadow_banned_until IS NULL OR users.shadow_banned_until > sqlc.arg(now)::timestamp)
+      )
+    )
+  )
+ORDER BY created_at ASC;
+
+
+-- name: GetDeletedChirpsByAuthor :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
 FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
\ No newline at end of file
+WHERE user_id = $1 AND deleted_at >= sqlc.arg(deleted_since)::timestamp
+ORDER BY deleted_at DESC;
+
+-- name: GetDeletedChirpByID :one
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = sqlc.arg(now)::timestamp
+WHERE id = sqlc.arg(id) AND deleted_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at;
+
+-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < sqlc.arg(deleted_before)::timestamp;
+
+
+-- name: CreateChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT sqlc.arg(chirp_id)::uuid, users.id
+FROM users
+WHERE users.id = ANY(sqlc.arg(user_ids)::uuid[])
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(author_id)::uuid AND blocks.blocked_id = users.id)
+       OR (blocks.blocker_id = users.id AND blocks.blocked_id = sqlc.arg(author_id)::uuid)
+  )
+ON CONFLICT DO NOTHING;
+
+
+-- name: HideChirp :exec
+UPDATE chirps
+SET hidden_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+
+-- name: CountChirpsByAuthorSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = $1 AND created_at >= sqlc.arg(since)::timestamp;
+
+-- name: CountDuplicateChirpsSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = $1 AND body = $2 AND created_at >= sqlc.arg(since)::timestamp;
@@ -0,0 +1,67 @@
+-- name: CreateFollow :exec
+WITH followed AS (
+    INSERT INTO follows (follower_id, followee_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING follower_id, followee_id
+)
+INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+SELECT gen_random_uuid(), 'follower.new', followee_id, jsonb_build_object('follower_id', follower_id), NOW()
+FROM followed;
+
+-- name: DeleteFollow :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+
+-- name: CreateFollowRequest :exec
+INSERT INTO follow_requests (requester_id, target_id, created_at)
+SELECT sqlc.arg(requester_id)::uuid, sqlc.arg(target_id)::uuid, NOW()
+WHERE NOT EXISTS (
+    SELECT 1 FROM follows
+    WHERE follower_id = sqlc.arg(requester_id)::uuid AND followee_id = sqlc.arg(target_id)::uuid
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollowRequest :execrows
+DELETE FROM follow_requests
+WHERE requester_id = $1 AND target_id = $2;
+
+-- name: GetFollowRequests :many
+SELECT follow_requests.requester_id, users.email, follow_requests.created_at
+FROM follow_requests
+JOIN users ON users.id = follow_requests.requester_id
+WHERE follow_requests.target_id = $1
+ORDER BY follow_requests.created_at ASC;
+
+-- name: ApproveFollowRequest :execrows
+WITH approved AS (
+    DELETE FROM follow_requests
+    WHERE requester_id = $1 AND target_id = $2
+    RETURNING requester_id, target_id
+), followed AS (
+    INSERT INTO follows (follower_id, followee_id, created_at)
+    SELECT requester_id, target_id, NOW()
+    FROM approved
+    ON CONFLICT DO NOTHING
+    RETURNING follower_id, followee_id
+)
+INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+SELECT gen_random_uuid(), 'follower.new', followee_id, jsonb_build_object('follower_id', follower_id), NOW()
+FROM followed;
+
+-- name: ApproveAllFollowRequests :exec
+WITH approved AS (
+    DELETE FROM follow_requests
+    WHERE target_id = $1
+    RETURNING requester_id, target_id
+), followed AS (
+    INSERT INTO follows (follower_id, followee_id, created_at)
+    SELECT requester_id, target_id, NOW()
+    FROM approved
+    ON CONFLICT DO NOTHING
+    RETURNING follower_id, followee_id
+)
+INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+SELECT gen_random_uuid(), 'follower.new', followee_id, jsonb_build_object('follower_id', follower_id), NOW()
+FROM followed;
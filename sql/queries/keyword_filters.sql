@@ -0,0 +1,18 @@
+-- name: CreateKeywordFilter :one
+INSERT INTO keyword_filters (id, user_id, phrase, whole_word, action, expires_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW())
+RETURNING *;
+
+-- name: ListKeywordFilters :many
+SELECT * FROM keyword_filters
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListActiveKeywordFilters :many
+SELECT * FROM keyword_filters
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > sqlc.arg(now)::timestamp)
+ORDER BY created_at ASC;
+
+-- name: DeleteKeywordFilter :execrows
+DELETE FROM keyword_filters
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,13 @@
+-- name: ListProfanityWords :many
+SELECT * FROM profanity_words ORDER BY word ASC;
+
+-- name: UpsertProfanityWord :one
+INSERT INTO profanity_words (word, action, created_at, updated_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (word) DO UPDATE
+SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteProfanityWord :execrows
+DELETE FROM profanity_words WHERE word = $1;
+
@@ -0,0 +1,90 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, user_id, url, secret, events, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW(), NOW())
+RETURNING *;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+WHERE user_id IS NOT DISTINCT FROM $1
+ORDER BY created_at ASC;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2;
+
+-- name: EnqueueOutboxEvent :exec
+INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW());
+
+-- name: EnqueueMentionEvents :exec
+INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+SELECT gen_random_uuid(), 'mention', chirp_mentions.user_id,
+       jsonb_build_object('chirp_id', chirp_mentions.chirp_id, 'author_id', sqlc.arg(author_id)::uuid),
+       NOW()
+FROM chirp_mentions
+WHERE chirp_mentions.chirp_id = sqlc.arg(chirp_id)::uuid;
+
+-- name: DispatchOutboxEvents :execrows
+WITH events AS (
+    UPDATE outbox_events
+    SET dispatched_at = NOW()
+    WHERE id IN (
+        SELECT id FROM outbox_events
+        WHERE dispatched_at IS NULL
+        ORDER BY created_at
+        LIMIT 100
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, event_type, user_id
+)
+INSERT INTO webhook_deliveries (id, endpoint_id, event_id, status, attempts, next_attempt_at, created_at, updated_at)
+SELECT gen_random_uuid(), webhook_endpoints.id, events.id, 'pending', 0, sqlc.arg(now)::timestamp, NOW(), NOW()
+FROM events
+JOIN webhook_endpoints
+  ON (webhook_endpoints.user_id IS NULL OR webhook_endpoints.user_id = events.user_id)
+ AND events.event_type = ANY(webhook_endpoints.events);
+
+-- name: ClaimDueDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(lease_until)::timestamp, updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)::timestamp
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: GetDeliveryTarget :one
+SELECT webhook_deliveries.id, webhook_deliveries.attempts,
+       webhook_endpoints.url, webhook_endpoints.secret,
+       outbox_events.id AS event_id, outbox_events.event_type, outbox_events.payload, outbox_events.created_at
+FROM webhook_deliveries
+JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id
+JOIN outbox_events ON outbox_events.id = webhook_deliveries.event_id
+WHERE webhook_deliveries.id = $1;
+
+-- name: MarkDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded', attempts = attempts + 1, last_status_code = $2, last_error = NULL,
+    delivered_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4,
+    next_attempt_at = $5, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListDeliveriesByEndpoint :many
+SELECT webhook_deliveries.*, outbox_events.event_type
+FROM webhook_deliveries
+JOIN outbox_events ON outbox_events.id = webhook_deliveries.event_id
+WHERE webhook_deliveries.endpoint_id = $1
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT 100;
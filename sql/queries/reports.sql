@@ -0,0 +1,23 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, reporter_id, target_user_id, target_chirp_id, category, details, status, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, 'open', NOW(), NOW())
+RETURNING *;
+
+-- name: GetReportByID :one
+SELECT * FROM reports WHERE id = $1;
+
+-- name: ListReportsByStatus :many
+SELECT * FROM reports
+WHERE status = $1
+ORDER BY created_at ASC;
+
+-- name: ResolveReport :one
+UPDATE reports
+SET status = $2, resolved_by = $3, resolved_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_actions (id, moderator_id, action, report_id, target_user_id, target_chirp_id, reason, expires_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, NOW());
@@ -0,0 +1,8 @@
+-- name: GetSetting :one
+SELECT value FROM settings WHERE key = $1;
+
+-- name: UpsertSetting :exec
+INSERT INTO settings (key, value, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (key) DO UPDATE
+SET value = EXCLUDED.value, updated_at = NOW();
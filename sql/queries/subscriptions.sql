@@ -0,0 +1,51 @@
+-- name: ActivateSubscription :one
+INSERT INTO subscriptions (user_id, plan, status, current_period_end, created_at, updated_at)
+VALUES ($1, $2, 'active', $3, NOW(), NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET plan = EXCLUDED.plan,
+    status = 'active',
+    current_period_end = EXCLUDED.current_period_end,
+    grace_period_end = NULL,
+    canceled_at = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetSubscriptionByUser :one
+SELECT * FROM subscriptions WHERE user_id = $1;
+
+-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'canceled', canceled_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND status IN ('active', 'past_due')
+RETURNING *;
+
+-- name: MarkSubscriptionPastDue :one
+UPDATE subscriptions
+SET status = 'past_due', grace_period_end = $2, updated_at = NOW()
+WHERE user_id = $1 AND status IN ('active', 'past_due')
+RETURNING *;
+
+-- name: RefundSubscription :one
+UPDATE subscriptions
+SET status = 'refunded', canceled_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND status <> 'refunded'
+RETURNING *;
+
+-- name: ExpireSubscriptions :execrows
+WITH expired AS (
+    UPDATE subscriptions
+    SET status = 'expired', updated_at = NOW()
+    WHERE (status = 'active' AND current_period_end < sqlc.arg(active_cutoff)::timestamp)
+       OR (status = 'past_due' AND grace_period_end < sqlc.arg(now)::timestamp)
+       OR (status = 'canceled' AND current_period_end < sqlc.arg(now)::timestamp)
+    RETURNING user_id, plan, current_period_end
+), events AS (
+    INSERT INTO outbox_events (id, event_type, user_id, payload, created_at)
+    SELECT gen_random_uuid(), 'subscription.changed', user_id,
+           jsonb_build_object('user_id', user_id, 'plan', plan, 'status', 'expired', 'current_period_end', current_period_end),
+           NOW()
+    FROM expired
+)
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+WHERE id IN (SELECT user_id FROM expired);
@@ -33,4 +33,63 @@ SET
   is_chirpy_red = true,
   updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, is_chirpy_red;
\ No newline at end of file
+RETURNING id, created_at, updated_at, email, is_chirpy_red;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
+
+
+-- name: SetUserPrivacy :one
+UPDATE users
+SET
+  is_private = $2,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, is_chirpy_red, is_private;
+
+
+-- name: SuspendUser :execrows
+UPDATE users
+SET
+  suspended_at = NOW(),
+  suspended_until = $2,
+  updated_at = NOW()
+WHERE id = $1;
+
+-- name: UnsuspendUser :execrows
+UPDATE users
+SET
+  suspended_at = NULL,
+  suspended_until = NULL,
+  updated_at = NOW()
+WHERE id = $1;
+
+-- name: ShadowBanUser :execrows
+UPDATE users
+SET
+  shadow_banned_at = NOW(),
+  shadow_banned_until = $2,
+  updated_at = NOW()
+WHERE id = $1;
+
+-- name: UnshadowBanUser :execrows
+UPDATE users
+SET
+  shadow_banned_at = NULL,
+  shadow_banned_until = NULL,
+  updated_at = NOW()
+WHERE id = $1;
+
+
+-- name: SetUserRole :one
+UPDATE users
+SET
+  role = $2,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING id, email, role;
+
+-- name: DowngradeUserFromChirpyRed :exec
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,39 @@
+-- name: RecordWebhookEvent :one
+-- Claims the event for processing. A failed event is claimed again, and so
+-- is one left 'received' for longer than the lease by a request that died
+-- before it finished.
+INSERT INTO webhook_events (id, provider, event_id, event_type, payload, status, attempts, received_at, claimed_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, 'received', 1, NOW(), NOW())
+ON CONFLICT (provider, event_id) DO UPDATE
+SET status = 'received', attempts = webhook_events.attempts + 1, claimed_at = NOW()
+WHERE webhook_events.status = 'failed'
+   OR (webhook_events.status = 'received' AND webhook_events.claimed_at < NOW() - INTERVAL '5 minutes')
+RETURNING *;
+
+-- name: GetWebhookEvent :one
+SELECT * FROM webhook_events WHERE id = $1;
+
+-- name: GetWebhookEventStatus :one
+SELECT status FROM webhook_events WHERE provider = $1 AND event_id = $2;
+
+-- name: ListWebhookEvents :many
+SELECT * FROM webhook_events
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY received_at DESC
+LIMIT 100;
+
+-- name: RetryWebhookEvent :one
+UPDATE webhook_events
+SET status = 'received', attempts = attempts + 1, claimed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET status = 'processed', last_error = NULL, processed_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookEventFailed :exec
+UPDATE webhook_events
+SET status = 'failed', last_error = $2
+WHERE id = $1;
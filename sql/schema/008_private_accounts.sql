@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE follow_requests (
+    requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (requester_id, target_id)
+);
+
+CREATE INDEX follow_requests_target_id_idx ON follow_requests(target_id);
+
+-- +goose Down
+DROP TABLE follow_requests;
+ALTER TABLE users DROP COLUMN is_private;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE profanity_words (
+    word TEXT PRIMARY KEY,
+    action TEXT NOT NULL DEFAULT 'mask' CHECK (action IN ('mask', 'reject', 'flag')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO profanity_words (word, action, created_at, updated_at) VALUES
+    ('kerfuffle', 'mask', NOW(), NOW()),
+    ('sharbert', 'mask', NOW(), NOW()),
+    ('fornax', 'mask', NOW(), NOW());
+
+CREATE TABLE chirp_flags (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX chirp_flags_unresolved_idx ON chirp_flags(created_at) WHERE resolved_at IS NULL;
+
+-- +goose Down
+DROP TABLE chirp_flags;
+DROP TABLE profanity_words;
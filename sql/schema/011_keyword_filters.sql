@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE keyword_filters (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phrase TEXT NOT NULL,
+    whole_word BOOLEAN NOT NULL DEFAULT false,
+    action TEXT NOT NULL DEFAULT 'hide' CHECK (action IN ('hide', 'collapse')),
+    expires_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX keyword_filters_user_id_idx ON keyword_filters(user_id);
+
+-- +goose Down
+DROP TABLE keyword_filters;
@@ -0,0 +1,71 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN is_moderator BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP DEFAULT NULL;
+
+ALTER TABLE chirps ADD COLUMN hidden_at TIMESTAMP DEFAULT NULL;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    reporter_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    category TEXT NOT NULL CHECK (category IN (
+        'spam', 'harassment', 'hate', 'violence', 'sexual', 'self_harm', 'profanity', 'other'
+    )),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports(status, created_at);
+
+-- Chirps flagged by the profanity filter join the same queue as user reports,
+-- filed by the system rather than a user.
+INSERT INTO reports (id, target_user_id, target_chirp_id, category, details, status, resolved_at, created_at, updated_at)
+SELECT chirp_flags.id, chirps.user_id, chirp_flags.chirp_id, 'profanity', chirp_flags.reason,
+       CASE WHEN chirp_flags.resolved_at IS NULL THEN 'open' ELSE 'resolved' END,
+       chirp_flags.resolved_at, chirp_flags.created_at, chirp_flags.created_at
+FROM chirp_flags
+JOIN chirps ON chirps.id = chirp_flags.chirp_id;
+
+DROP TABLE chirp_flags;
+
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    report_id UUID REFERENCES reports(id) ON DELETE SET NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX moderation_actions_target_user_id_idx ON moderation_actions(target_user_id);
+
+-- +goose Down
+DROP TABLE moderation_actions;
+
+CREATE TABLE chirp_flags (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX chirp_flags_unresolved_idx ON chirp_flags(created_at) WHERE resolved_at IS NULL;
+
+INSERT INTO chirp_flags (id, chirp_id, reason, created_at, resolved_at)
+SELECT id, target_chirp_id, details, created_at, resolved_at
+FROM reports
+WHERE reporter_id IS NULL AND category = 'profanity' AND target_chirp_id IS NOT NULL;
+
+DROP TABLE reports;
+
+ALTER TABLE chirps DROP COLUMN hidden_at;
+ALTER TABLE users DROP COLUMN suspended_at;
+ALTER TABLE users DROP COLUMN is_moderator;
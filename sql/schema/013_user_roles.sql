@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+UPDATE users SET role = 'moderator' WHERE is_moderator;
+
+ALTER TABLE users DROP COLUMN is_moderator;
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN is_moderator BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE users SET is_moderator = true WHERE role IN ('moderator', 'admin');
+
+ALTER TABLE users DROP COLUMN role;
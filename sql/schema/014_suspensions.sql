@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP DEFAULT NULL;
+ALTER TABLE users ADD COLUMN shadow_banned_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE users ADD COLUMN shadow_banned_until TIMESTAMP DEFAULT NULL;
+
+ALTER TABLE moderation_actions ADD COLUMN expires_at TIMESTAMP DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE moderation_actions DROP COLUMN expires_at;
+
+ALTER TABLE users DROP COLUMN shadow_banned_until;
+ALTER TABLE users DROP COLUMN shadow_banned_at;
+ALTER TABLE users DROP COLUMN suspended_until;
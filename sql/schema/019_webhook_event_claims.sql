@@ -0,0 +1,7 @@
+-- +goose Up
+-- claimed_at is when a delivery last took the event for processing, so a
+-- claim whose request died before finishing can be taken over.
+ALTER TABLE webhook_events ADD COLUMN claimed_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE webhook_events DROP COLUMN claimed_at;
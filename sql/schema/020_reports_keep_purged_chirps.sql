@@ -0,0 +1,12 @@
+-- +goose Up
+-- Purging a chirp from the trash must not take its reports with it: an open
+-- report still needs a decision about the author, and closed ones are the
+-- record of what was decided. moderation_actions already works this way.
+ALTER TABLE reports DROP CONSTRAINT reports_target_chirp_id_fkey;
+ALTER TABLE reports ADD CONSTRAINT reports_target_chirp_id_fkey
+    FOREIGN KEY (target_chirp_id) REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE reports DROP CONSTRAINT reports_target_chirp_id_fkey;
+ALTER TABLE reports ADD CONSTRAINT reports_target_chirp_id_fkey
+    FOREIGN KEY (target_chirp_id) REFERENCES chirps(id) ON DELETE CASCADE;
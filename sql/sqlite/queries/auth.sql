@@ -0,0 +1,16 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens WHERE token = ? LIMIT 1;
+
+-- name: RevokeRefreshToken :exec
+UPDATE refresh_tokens
+SET revoked_at = ?, updated_at = ?
+WHERE token = ?;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = sqlc.arg(now), updated_at = sqlc.arg(now)
+WHERE user_id = ? AND revoked_at IS NULL;
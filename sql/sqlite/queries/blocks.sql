@@ -0,0 +1,9 @@
+-- name: CreateBlock :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: CreateMute :exec
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING;
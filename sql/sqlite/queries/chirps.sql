@@ -0,0 +1,168 @@
+-- name: CreateChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, visibility)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at;
+
+-- name: GetAllChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.deleted_at, chirps.visibility, chirps.hidden_at
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id))
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id) AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    chirps.user_id = sqlc.arg(viewer_id)
+    OR (
+      chirps.hidden_at IS NULL
+      AND (
+        chirps.visibility = 'public'
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        ))
+        OR (chirps.visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)
+        ))
+      )
+      AND (
+        NOT users.is_private
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND (users.shadow_banned_at IS NULL OR users.shadow_banned_until <= sqlc.arg(now))
+    )
+  )
+ORDER BY chirps.created_at ASC;
+
+-- name: GetChirpsByAuthor :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.deleted_at, chirps.visibility, chirps.hidden_at
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.user_id = sqlc.arg(user_id) AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id))
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id) AND mutes.muted_id = chirps.user_id
+  )
+  AND (
+    chirps.user_id = sqlc.arg(viewer_id)
+    OR (
+      chirps.hidden_at IS NULL
+      AND (
+        chirps.visibility = 'public'
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        ))
+        OR (chirps.visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)
+        ))
+      )
+      AND (
+        NOT users.is_private
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND (users.shadow_banned_at IS NULL OR users.shadow_banned_until <= sqlc.arg(now))
+    )
+  )
+ORDER BY chirps.created_at ASC;
+
+-- name: GetChirpByID :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.deleted_at, chirps.visibility, chirps.hidden_at
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = sqlc.arg(id) AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+       OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg(viewer_id))
+  )
+  AND (
+    chirps.user_id = sqlc.arg(viewer_id)
+    OR (
+      chirps.hidden_at IS NULL
+      AND (
+        chirps.visibility IN ('public', 'unlisted')
+        OR (chirps.visibility = 'followers' AND EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        ))
+        OR (chirps.visibility = 'mentioned' AND EXISTS (
+            SELECT 1 FROM chirp_mentions
+            WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = sqlc.arg(viewer_id)
+        ))
+      )
+      AND (
+        NOT users.is_private
+        OR EXISTS (
+            SELECT 1 FROM follows
+            WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id
+        )
+      )
+      AND (users.shadow_banned_at IS NULL OR users.shadow_banned_until <= sqlc.arg(now))
+    )
+  );
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = sqlc.arg(now), updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
+
+-- name: GetDeletedChirpsByAuthor :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE user_id = sqlc.arg(user_id) AND deleted_at >= sqlc.arg(deleted_since)
+ORDER BY deleted_at DESC;
+
+-- name: GetDeletedChirpByID :one
+SELECT id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at
+FROM chirps
+WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL, updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND deleted_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, visibility, hidden_at;
+
+-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < sqlc.arg(deleted_before);
+
+-- name: CreateChirpMention :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT sqlc.arg(chirp_id), users.id
+FROM users
+WHERE users.id = sqlc.arg(user_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg(author_id) AND blocks.blocked_id = users.id)
+       OR (blocks.blocker_id = users.id AND blocks.blocked_id = sqlc.arg(author_id))
+  )
+ON CONFLICT DO NOTHING;
+
+-- name: CountChirpsByAuthorSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = ? AND created_at >= sqlc.arg(since);
+
+-- name: CountDuplicateChirpsSince :one
+SELECT COUNT(*) FROM chirps
+WHERE user_id = ? AND body = ? AND created_at >= sqlc.arg(since);
@@ -0,0 +1,13 @@
+-- name: CreateFollow :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: CreateFollowRequest :exec
+INSERT INTO follow_requests (requester_id, target_id, created_at)
+SELECT sqlc.arg(requester_id), sqlc.arg(target_id), sqlc.arg(created_at)
+WHERE NOT EXISTS (
+    SELECT 1 FROM follows
+    WHERE follower_id = sqlc.arg(requester_id) AND followee_id = sqlc.arg(target_id)
+)
+ON CONFLICT DO NOTHING;
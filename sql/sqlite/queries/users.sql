@@ -0,0 +1,54 @@
+-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = ?;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = ?;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+  email = ?,
+  hashed_password = ?,
+  updated_at = ?
+WHERE id = ?
+RETURNING id, created_at, updated_at, email, is_chirpy_red;
+
+-- name: DeleteAllUsers :exec
+DELETE FROM users;
+
+-- name: SetUserRole :one
+UPDATE users
+SET
+  role = ?,
+  updated_at = ?
+WHERE id = ?
+RETURNING id, email, role;
+
+-- name: SetUserPrivacy :one
+UPDATE users
+SET
+  is_private = ?,
+  updated_at = ?
+WHERE id = ?
+RETURNING id, created_at, updated_at, email, is_chirpy_red, is_private;
+
+-- name: SuspendUser :execrows
+UPDATE users
+SET
+  suspended_at = sqlc.arg(now),
+  suspended_until = sqlc.arg(suspended_until),
+  updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
+
+-- name: ShadowBanUser :execrows
+UPDATE users
+SET
+  shadow_banned_at = sqlc.arg(now),
+  shadow_banned_until = sqlc.arg(shadow_banned_until),
+  updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,48 @@
+-- +goose Up
+-- SQLite has no UUID type, so ids are stored as text and generated by the
+-- application. Columns follow the order of the Postgres tables so the
+-- generated models line up.
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    hashed_password TEXT NOT NULL DEFAULT 'unset',
+    is_chirpy_red BOOLEAN NOT NULL DEFAULT false,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    suspended_at DATETIME,
+    role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin')),
+    suspended_until DATETIME,
+    shadow_banned_at DATETIME,
+    shadow_banned_until DATETIME
+);
+
+CREATE TABLE refresh_tokens (
+    token TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens(user_id);
+
+CREATE TABLE chirps (
+    id TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    body TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deleted_at DATETIME,
+    visibility TEXT NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'unlisted', 'followers', 'mentioned')),
+    hidden_at DATETIME
+);
+
+CREATE INDEX chirps_user_id_created_at_idx ON chirps(user_id, created_at);
+
+-- +goose Down
+DROP TABLE chirps;
+DROP TABLE refresh_tokens;
+DROP TABLE users;
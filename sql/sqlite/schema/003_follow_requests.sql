@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE follow_requests (
+    requester_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (requester_id, target_id)
+);
+
+CREATE INDEX follow_requests_target_id_idx ON follow_requests(target_id);
+
+-- +goose Down
+DROP TABLE follow_requests;
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE blocks (
+    blocker_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks(blocked_id);
+
+CREATE TABLE mutes (
+    muter_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (muter_id, muted_id)
+);
+
+-- The Postgres CreateBlock query drops the follows and follow requests
+-- between the two users in the same statement. SQLite cannot delete inside
+-- a WITH clause, so a trigger does it instead.
+-- +goose StatementBegin
+CREATE TRIGGER blocks_remove_follows AFTER INSERT ON blocks
+BEGIN
+    DELETE FROM follows
+    WHERE (follower_id = NEW.blocker_id AND followee_id = NEW.blocked_id)
+       OR (follower_id = NEW.blocked_id AND followee_id = NEW.blocker_id);
+    DELETE FROM follow_requests
+    WHERE (requester_id = NEW.blocker_id AND target_id = NEW.blocked_id)
+       OR (requester_id = NEW.blocked_id AND target_id = NEW.blocker_id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER blocks_remove_follows;
+DROP TABLE mutes;
+DROP TABLE blocks;
@@ -0,0 +1,246 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/config"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/database/sqlite"
+    "github.com/KrishKoria/Chirpy/internal/health"
+    "github.com/KrishKoria/Chirpy/internal/migrations"
+    "github.com/KrishKoria/Chirpy/internal/replica"
+    "github.com/KrishKoria/Chirpy/internal/store"
+    "github.com/KrishKoria/Chirpy/internal/tracing"
+    "github.com/google/uuid"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// openStorage sets up the backend DB_URL selects. The in-memory store only
+// sets cfg.Store and cfg.Extras. SQLite also sets Conn and Migrations and
+// registers the database health checks and metrics. Postgres fills in DB as
+// well, which the features beyond accounts and chirps need.
+func (cfg *APIConfig) openStorage(appConfig config.Config) error {
+    switch appConfig.Storage() {
+    case config.StorageMemory:
+        cfg.Logger.Warn("using in-memory storage; data is lost on exit and only accounts and chirps are available")
+        cfg.Store = store.NewMemory()
+        cfg.Extras = noExtras{}
+        return nil
+    case config.StorageSQLite:
+        return cfg.openSQLite(appConfig)
+    }
+
+    db, err := sql.Open("postgres", appConfig.DatabaseURL)
+    if err != nil {
+        return err
+    }
+    db.SetMaxOpenConns(appConfig.DBMaxOpenConns)
+    db.SetMaxIdleConns(appConfig.DBMaxIdleConns)
+    db.SetConnMaxLifetime(appConfig.DBConnMaxLife)
+    db.SetConnMaxIdleTime(appConfig.DBConnMaxIdle)
+
+    migrator, err := migrations.New(db, schemaFS)
+    if err != nil {
+        db.Close()
+        return err
+    }
+
+    var queries tracing.DBTX = db
+    if len(appConfig.ReplicaURLs) > 0 {
+        router, err := openReplicas(db, appConfig)
+        if err != nil {
+            db.Close()
+            return err
+        }
+        cfg.Replicas = router
+        queries = router
+        for _, r := range router.Replicas() {
+            cfg.HealthChecks = append(cfg.HealthChecks, health.Check{Name: r.Name, Run: pingReplica(r)})
+        }
+    }
+
+    cfg.Conn = db
+    cfg.DB = database.New(tracing.WrapDB(queries, semconv.DBSystemPostgreSQL))
+    cfg.Store = store.NewSQL(cfg.DB)
+    cfg.Extras = cfg.DB
+    cfg.Migrations = migrator
+
+    cfg.registerDB(db)
+    return nil
+}
+
+// replicaReads are the queries the replicas serve. They are the hot,
+// read-only chirp listings; everything else, including the lookups done
+// while authenticating, stays on the primary.
+var replicaReads = []string{"GetAllChirps", "GetChirpByID", "GetChirpsByAuthor"}
+
+// openReplicas opens every DB_REPLICA_URLS entry with the primary's pool
+// settings and puts them behind a Router. Nothing is pinged here: a replica
+// that is down at startup leaves the rotation on its first failed query.
+func openReplicas(primary *sql.DB, appConfig config.Config) (*replica.Router, error) {
+    var replicas []*sql.DB
+    for _, url := range appConfig.ReplicaURLs {
+        db, err := sql.Open("postgres", url)
+        if err != nil {
+            for _, opened := range replicas {
+                opened.Close()
+            }
+            return nil, err
+        }
+        db.SetMaxOpenConns(appConfig.DBMaxOpenConns)
+        db.SetMaxIdleConns(appConfig.DBMaxIdleConns)
+        db.SetConnMaxLifetime(appConfig.DBConnMaxLife)
+        db.SetConnMaxIdleTime(appConfig.DBConnMaxIdle)
+        replicas = append(replicas, db)
+    }
+    return replica.NewRouter(primary, replicas, replicaReads, appConfig.ReplicaStickiness), nil
+}
+
+// pingReplica reports a replica's health. It is not critical, since reads
+// fall back to the primary while a replica is down.
+func pingReplica(r *replica.Replica) func(ctx context.Context) (any, error) {
+    return func(ctx context.Context) (any, error) {
+        return map[string]bool{"in_rotation": r.Healthy()}, r.Ping(ctx)
+    }
+}
+
+// checkReplicas pings the replicas every interval so those that went down
+// rejoin once they answer again, and drops expired read-your-writes
+// entries.
+func (cfg *APIConfig) checkReplicas(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+
+        healthy := map[string]bool{}
+        for _, r := range cfg.Replicas.Replicas() {
+            healthy[r.Name] = r.Healthy()
+        }
+        cfg.Replicas.Check(ctx)
+        for _, r := range cfg.Replicas.Replicas() {
+            switch {
+            case healthy[r.Name] && !r.Healthy():
+                cfg.Logger.Warn("replica out of rotation; reads go to the primary", "replica", r.Name)
+            case !healthy[r.Name] && r.Healthy():
+                cfg.Logger.Info("replica back in rotation", "replica", r.Name)
+            }
+        }
+        cfg.Replicas.Forget()
+    }
+}
+
+// openSQLite opens the database file with foreign keys on, which the
+// cascading deletes rely on, and in WAL mode so readers don't wait for the
+// writer. Transactions take the write lock up front, and a busy connection
+// waits for it rather than failing with "database is locked".
+func (cfg *APIConfig) openSQLite(appConfig config.Config) error {
+    dsn := "file:" + appConfig.SQLitePath() + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+    db, err := sql.Open("sqlite3", dsn)
+    if err != nil {
+        return err
+    }
+    db.SetMaxOpenConns(appConfig.DBMaxOpenConns)
+    db.SetMaxIdleConns(appConfig.DBMaxIdleConns)
+    db.SetConnMaxLifetime(appConfig.DBConnMaxLife)
+    db.SetConnMaxIdleTime(appConfig.DBConnMaxIdle)
+
+    migrator, err := migrations.NewSQLite(db, sqliteSchemaFS)
+    if err != nil {
+        db.Close()
+        return err
+    }
+
+    cfg.Logger.Info("using SQLite storage; only accounts and chirps are available", "path", appConfig.SQLitePath())
+    cfg.Conn = db
+    cfg.Store = store.NewSQLite(sqlite.New(tracing.WrapDB(db, semconv.DBSystemSqlite)))
+    cfg.Extras = noExtras{}
+    cfg.Migrations = migrator
+    cfg.registerDB(db)
+    return nil
+}
+
+// Extras is what the account and chirp endpoints read from the tables only
+// Postgres has: the subscription that sets a Chirpy Red user's limits, and
+// the keyword filters a viewer hides chirps with.
+type Extras interface {
+    GetSubscriptionByUser(ctx context.Context, userID uuid.UUID) (database.Subscription, error)
+    ListActiveKeywordFilters(ctx context.Context, arg database.ListActiveKeywordFiltersParams) ([]database.KeywordFilter, error)
+}
+
+// noExtras is Extras for the other backends. It answers as Postgres would
+// with the tables empty, so Chirpy Red users get the default Red limits and
+// nobody has filters.
+type noExtras struct{}
+
+func (noExtras) GetSubscriptionByUser(ctx context.Context, userID uuid.UUID) (database.Subscription, error) {
+    return database.Subscription{}, sql.ErrNoRows
+}
+
+func (noExtras) ListActiveKeywordFilters(ctx context.Context, arg database.ListActiveKeywordFiltersParams) ([]database.KeywordFilter, error) {
+    return nil, nil
+}
+
+// chirpTx is what creating or deleting a chirp writes to: the chirp itself,
+// and the review reports, mentions and outbound events that go with it.
+type chirpTx interface {
+    CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error)
+    SoftDeleteChirp(ctx context.Context, arg database.SoftDeleteChirpParams) error
+    CreateReport(ctx context.Context, arg database.CreateReportParams) (database.Report, error)
+    CreateChirpMentions(ctx context.Context, arg database.CreateChirpMentionsParams) error
+    EnqueueMentionEvents(ctx context.Context, arg database.EnqueueMentionEventsParams) error
+    EnqueueOutboxEvent(ctx context.Context, arg database.EnqueueOutboxEventParams) error
+}
+
+// inChirpTx runs fn in a Postgres transaction. The other backends have no
+// tables for reports or outbound events, so fn writes the chirp and its
+// mentions straight to the Store and the rest is dropped.
+func (cfg *APIConfig) inChirpTx(ctx context.Context, fn func(tx chirpTx) error) error {
+    if cfg.DB == nil {
+        return fn(chirpsOnly{cfg.Store})
+    }
+    return cfg.inTx(ctx, func(q *database.Queries) error {
+        return fn(q)
+    })
+}
+
+// chirpsOnly is a chirpTx that keeps what the Store holds and drops the rest.
+type chirpsOnly struct {
+    store.Store
+}
+
+func (chirpsOnly) CreateReport(ctx context.Context, arg database.CreateReportParams) (database.Report, error) {
+    return database.Report{}, nil
+}
+
+func (chirpsOnly) EnqueueMentionEvents(ctx context.Context, arg database.EnqueueMentionEventsParams) error {
+    return nil
+}
+
+func (chirpsOnly) EnqueueOutboxEvent(ctx context.Context, arg database.EnqueueOutboxEventParams) error {
+    return nil
+}
+
+func (cfg *APIConfig) registerDB(db *sql.DB) {
+    cfg.Metrics.RegisterDB(db)
+    cfg.HealthChecks = append(cfg.HealthChecks,
+        health.Ping("database", db),
+        health.Check{Name: "migrations", Critical: true, Run: cfg.checkSchemaVersion},
+    )
+}
+
+func (cfg *APIConfig) closeStorage() {
+    if cfg.Replicas != nil {
+        cfg.Replicas.Close()
+    }
+    if cfg.Conn != nil {
+        cfg.Conn.Close()
+    }
+}
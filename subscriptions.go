@@ -0,0 +1,194 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/google/uuid"
+)
+
+const (
+    planChirpyRed = "chirpy_red"
+
+    subscriptionStatusActive   = "active"
+    subscriptionStatusPastDue  = "past_due"
+    subscriptionStatusCanceled = "canceled"
+    subscriptionStatusExpired  = "expired"
+    subscriptionStatusRefunded = "refunded"
+
+    // subscriptionPeriod is used when a billing event does not say when the
+    // paid period ends.
+    subscriptionPeriod = 30 * 24 * time.Hour
+)
+
+var errNoSubscription = errors.New("subscription not found")
+
+// subscriptionEvent is the payload of subscription.changed outbound events.
+func subscriptionEvent(subscription database.Subscription) map[string]any {
+    return map[string]any{
+        "user_id":            subscription.UserID,
+        "plan":               subscription.Plan,
+        "status":             subscription.Status,
+        "current_period_end": subscription.CurrentPeriodEnd,
+    }
+}
+
+// subscriptionActive reports whether a subscription in status still grants
+// Chirpy Red. Canceled and past-due subscriptions keep it until they expire.
+func subscriptionActive(status string) bool {
+    return status != subscriptionStatusExpired && status != subscriptionStatusRefunded
+}
+
+// activateSubscription starts or renews a subscription and grants Chirpy Red.
+func (cfg *APIConfig) activateSubscription(ctx context.Context, userID uuid.UUID, plan string, periodEnd time.Time) error {
+    if plan == "" {
+        plan = planChirpyRed
+    }
+    if periodEnd.IsZero() {
+        periodEnd = time.Now().UTC().Add(subscriptionPeriod)
+    }
+
+    return cfg.inTx(ctx, func(q *database.Queries) error {
+        _, err := q.UpgradeUserToChirpyRed(ctx, userID)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                return errWebhookUserNotFound
+            }
+            return err
+        }
+
+        subscription, err := q.ActivateSubscription(ctx, database.ActivateSubscriptionParams{
+            UserID:           userID,
+            Plan:             plan,
+            CurrentPeriodEnd: periodEnd.UTC(),
+        })
+        if err != nil {
+            return err
+        }
+
+        return enqueueEvent(ctx, q, eventSubscriptionChanged, userID, subscriptionEvent(subscription))
+    })
+}
+
+// cancelSubscription stops renewal. Chirpy Red stays until the paid period
+// runs out and the expiry job removes it.
+func (cfg *APIConfig) cancelSubscription(ctx context.Context, userID uuid.UUID) error {
+    return cfg.inTx(ctx, func(q *database.Queries) error {
+        subscription, err := q.CancelSubscription(ctx, userID)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                return errNoSubscription
+            }
+            return err
+        }
+
+        return enqueueEvent(ctx, q, eventSubscriptionChanged, userID, subscriptionEvent(subscription))
+    })
+}
+
+// markSubscriptionPastDue keeps Chirpy Red for the grace period so the user
+// has time to fix their payment details.
+func (cfg *APIConfig) markSubscriptionPastDue(ctx context.Context, userID uuid.UUID) error {
+    return cfg.inTx(ctx, func(q *database.Queries) error {
+        subscription, err := q.MarkSubscriptionPastDue(ctx, database.MarkSubscriptionPastDueParams{
+            UserID:         userID,
+            GracePeriodEnd: sql.NullTime{Time: time.Now().UTC().Add(cfg.SubscriptionGrace), Valid: true},
+        })
+        if err != nil {
+            if err == sql.ErrNoRows {
+                return errNoSubscription
+            }
+            return err
+        }
+
+        return enqueueEvent(ctx, q, eventSubscriptionChanged, userID, subscriptionEvent(subscription))
+    })
+}
+
+// refundSubscription ends a subscription and removes Chirpy Red immediately.
+func (cfg *APIConfig) refundSubscription(ctx context.Context, userID uuid.UUID) error {
+    return cfg.inTx(ctx, func(q *database.Queries) error {
+        subscription, err := q.RefundSubscription(ctx, userID)
+        if err != nil {
+            if err == sql.ErrNoRows {
+                return errNoSubscription
+            }
+            return err
+        }
+
+        if err := q.DowngradeUserFromChirpyRed(ctx, userID); err != nil {
+            return err
+        }
+
+        return enqueueEvent(ctx, q, eventSubscriptionChanged, userID, subscriptionEvent(subscription))
+    })
+}
+
+// expireSubscriptions removes Chirpy Red from users whose paid period, or
+// grace period, has run out. It runs until ctx is cancelled.
+func (cfg *APIConfig) expireSubscriptions(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        now := time.Now().UTC()
+        _, err := cfg.DB.ExpireSubscriptions(ctx, database.ExpireSubscriptionsParams{
+            ActiveCutoff: now.Add(-cfg.SubscriptionGrace),
+            Now:          now,
+        })
+        if err != nil {
+            cfg.Logger.Error("expire subscriptions", "error", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (cfg *APIConfig) getSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    subscription, err := cfg.DB.GetSubscriptionByUser(r.Context(), userID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "No subscription")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve subscription", err)
+        return
+    }
+
+    type subscriptionResponse struct {
+        Plan             string     `json:"plan"`
+        Status           string     `json:"status"`
+        Active           bool       `json:"active"`
+        CurrentPeriodEnd time.Time  `json:"current_period_end"`
+        GracePeriodEnd   *time.Time `json:"grace_period_end,omitempty"`
+        CanceledAt       *time.Time `json:"canceled_at,omitempty"`
+    }
+
+    response := subscriptionResponse{
+        Plan:             subscription.Plan,
+        Status:           subscription.Status,
+        Active:           subscriptionActive(subscription.Status),
+        CurrentPeriodEnd: subscription.CurrentPeriodEnd,
+    }
+    if subscription.GracePeriodEnd.Valid {
+        response.GracePeriodEnd = &subscription.GracePeriodEnd.Time
+    }
+    if subscription.CanceledAt.Valid {
+        response.CanceledAt = &subscription.CanceledAt.Time
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
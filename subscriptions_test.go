@@ -0,0 +1,37 @@
+package main
+
+import (
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionActive(t *testing.T) {
+    assert.True(t, subscriptionActive(subscriptionStatusActive))
+    assert.True(t, subscriptionActive(subscriptionStatusPastDue))
+    assert.True(t, subscriptionActive(subscriptionStatusCanceled))
+    assert.False(t, subscriptionActive(subscriptionStatusExpired))
+    assert.False(t, subscriptionActive(subscriptionStatusRefunded))
+}
+
+func TestSubscriptionEvent(t *testing.T) {
+    userID := uuid.New()
+    periodEnd := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+    event := subscriptionEvent(database.Subscription{
+        UserID:           userID,
+        Plan:             planChirpyRed,
+        Status:           subscriptionStatusPastDue,
+        CurrentPeriodEnd: periodEnd,
+    })
+
+    assert.Equal(t, map[string]any{
+        "user_id":            userID,
+        "plan":               planChirpyRed,
+        "status":             subscriptionStatusPastDue,
+        "current_period_end": periodEnd,
+    }, event)
+}
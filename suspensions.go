@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+    moderationUnsuspendUser   = "unsuspend_user"
+    moderationShadowBanUser   = "shadow_ban_user"
+    moderationUnshadowBanUser = "unshadow_ban_user"
+)
+
+// userSuspended reports whether user is currently suspended. A suspension
+// without an end time lasts until it is lifted.
+func userSuspended(user database.User) bool {
+    if !user.SuspendedAt.Valid {
+        return false
+    }
+    return !user.SuspendedUntil.Valid || time.Now().UTC().Before(user.SuspendedUntil.Time)
+}
+
+// userShadowBanned reports whether user is currently shadow-banned. Like a
+// suspension, a shadow-ban without an end time lasts until it is lifted.
+func userShadowBanned(user database.User) bool {
+    if !user.ShadowBannedAt.Valid {
+        return false
+    }
+    return !user.ShadowBannedUntil.Valid || time.Now().UTC().Before(user.ShadowBannedUntil.Time)
+}
+
+// suspendUser suspends userID until the given time, or indefinitely, and
+// revokes every refresh token so existing sessions cannot be renewed. Pass
+// the transaction's queries to make it part of a larger change.
+func suspendUser(ctx context.Context, q *database.Queries, userID uuid.UUID, until sql.NullTime) error {
+    updated, err := q.SuspendUser(ctx, database.SuspendUserParams{
+        ID:             userID,
+        SuspendedUntil: until,
+    })
+    if err != nil {
+        return err
+    }
+    if updated == 0 {
+        return sql.ErrNoRows
+    }
+
+    return q.RevokeAllRefreshTokensForUser(ctx, userID)
+}
+
+type sanctionRequest struct {
+    Reason          string `json:"reason"`
+    DurationSeconds *int   `json:"duration_seconds,omitempty"`
+}
+
+// decodeSanction reads the reason and optional duration shared by the
+// suspension and shadow-ban endpoints.
+func decodeSanction(w http.ResponseWriter, r *http.Request) (string, sql.NullTime, bool) {
+    var req sanctionRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return "", sql.NullTime{}, false
+    }
+
+    reason := strings.TrimSpace(req.Reason)
+    if reason == "" {
+        respondWithError(w, http.StatusBadRequest, "Reason is required")
+        return "", sql.NullTime{}, false
+    }
+
+    var until sql.NullTime
+    if req.DurationSeconds != nil {
+        if *req.DurationSeconds <= 0 {
+            respondWithError(w, http.StatusBadRequest, "duration_seconds must be positive")
+            return "", sql.NullTime{}, false
+        }
+        until = sql.NullTime{
+            Time:  time.Now().UTC().Add(time.Duration(*req.DurationSeconds) * time.Second),
+            Valid: true,
+        }
+    }
+
+    return reason, until, true
+}
+
+// recordSanction logs a moderator's action against userID. Pass the same
+// transaction's queries as the sanction itself so neither lands alone.
+func recordSanction(ctx context.Context, q *database.Queries, moderatorID, userID uuid.UUID, action, reason string, until sql.NullTime) error {
+    return q.CreateModerationAction(ctx, database.CreateModerationActionParams{
+        ModeratorID:  uuid.NullUUID{UUID: moderatorID, Valid: true},
+        Action:       action,
+        TargetUserID: uuid.NullUUID{UUID: userID, Valid: true},
+        Reason:       reason,
+        ExpiresAt:    until,
+    })
+}
+
+func (cfg *APIConfig) suspendUserHandler(w http.ResponseWriter, r *http.Request) {
+    moderatorID := userIDFromContext(r.Context())
+
+    userID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if userID == moderatorID {
+        respondWithError(w, http.StatusBadRequest, "You cannot suspend yourself")
+        return
+    }
+
+    reason, until, ok := decodeSanction(w, r)
+    if !ok {
+        return
+    }
+
+    err = cfg.inTx(r.Context(), func(q *database.Queries) error {
+        if err := suspendUser(r.Context(), q, userID, until); err != nil {
+            return err
+        }
+        return recordSanction(r.Context(), q, moderatorID, userID, moderationSuspendUser, reason, until)
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to suspend user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) unsuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+    moderatorID := userIDFromContext(r.Context())
+
+    userID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    reason, _, ok := decodeSanction(w, r)
+    if !ok {
+        return
+    }
+
+    err = cfg.inTx(r.Context(), func(q *database.Queries) error {
+        updated, err := q.UnsuspendUser(r.Context(), userID)
+        if err != nil {
+            return err
+        }
+        if updated == 0 {
+            return sql.ErrNoRows
+        }
+        return recordSanction(r.Context(), q, moderatorID, userID, moderationUnsuspendUser, reason, sql.NullTime{})
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to lift suspension", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) shadowBanUserHandler(w http.ResponseWriter, r *http.Request) {
+    moderatorID := userIDFromContext(r.Context())
+
+    userID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if userID == moderatorID {
+        respondWithError(w, http.StatusBadRequest, "You cannot shadow-ban yourself")
+        return
+    }
+
+    reason, until, ok := decodeSanction(w, r)
+    if !ok {
+        return
+    }
+
+    err = cfg.inTx(r.Context(), func(q *database.Queries) error {
+        updated, err := q.ShadowBanUser(r.Context(), database.ShadowBanUserParams{
+            ID:                userID,
+            ShadowBannedUntil: until,
+        })
+        if err != nil {
+            return err
+        }
+        if updated == 0 {
+            return sql.ErrNoRows
+        }
+        return recordSanction(r.Context(), q, moderatorID, userID, moderationShadowBanUser, reason, until)
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to shadow-ban user", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *APIConfig) unshadowBanUserHandler(w http.ResponseWriter, r *http.Request) {
+    moderatorID := userIDFromContext(r.Context())
+
+    userID, err := uuid.Parse(r.PathValue("userID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    reason, _, ok := decodeSanction(w, r)
+    if !ok {
+        return
+    }
+
+    err = cfg.inTx(r.Context(), func(q *database.Queries) error {
+        updated, err := q.UnshadowBanUser(r.Context(), userID)
+        if err != nil {
+            return err
+        }
+        if updated == 0 {
+            return sql.ErrNoRows
+        }
+        return recordSanction(r.Context(), q, moderatorID, userID, moderationUnshadowBanUser, reason, sql.NullTime{})
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to lift shadow-ban", err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/KrishKoria/Chirpy/internal/store"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestSuspendedUsersAreRejected(t *testing.T) {
+    ctx := context.Background()
+    cfg := &APIConfig{
+        Store:     store.NewMemory(),
+        JWTSecret: "secret",
+        Metrics:   metrics.New(),
+    }
+    hash, err := auth.HashPassword("password")
+    require.NoError(t, err)
+
+    suspend := func(email string, until time.Duration) uuid.UUID {
+        user, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: email, HashedPassword: hash})
+        require.NoError(t, err)
+        _, err = cfg.Store.SuspendUser(ctx, database.SuspendUserParams{
+            ID:             user.ID,
+            SuspendedUntil: sql.NullTime{Time: time.Now().UTC().Add(until), Valid: true},
+        })
+        require.NoError(t, err)
+        return user.ID
+    }
+    login := func(email string) int {
+        body := `{"email": "` + email + `", "password": "password"}`
+        rec := httptest.NewRecorder()
+        cfg.loginHandler(rec, httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body)))
+        return rec.Code
+    }
+    listTrash := func(userID uuid.UUID) int {
+        token, err := auth.MakeJWT(userID, cfg.JWTSecret, time.Hour)
+        require.NoError(t, err)
+        req := httptest.NewRequest(http.MethodGet, "/api/chirps/trash", nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        rec := httptest.NewRecorder()
+        cfg.getTrashHandler(rec, req)
+        return rec.Code
+    }
+
+    suspended := suspend("suspended@example.com", time.Hour)
+    assert.Equal(t, http.StatusForbidden, login("suspended@example.com"))
+    assert.Equal(t, http.StatusForbidden, listTrash(suspended))
+
+    served := suspend("served@example.com", -time.Minute)
+    assert.Equal(t, http.StatusOK, login("served@example.com"))
+    assert.Equal(t, http.StatusOK, listTrash(served))
+}
+
+// Sanction requests are checked before anything is written, so these run
+// without a database.
+func TestSanctionRequestValidation(t *testing.T) {
+    cfg := &APIConfig{}
+    moderatorID := uuid.New()
+
+    tests := []struct {
+        name   string
+        target string
+        body   string
+    }{
+        {"invalid user", "not-a-uuid", `{"reason": "spam"}`},
+        {"self", moderatorID.String(), `{"reason": "spam"}`},
+        {"no reason", uuid.NewString(), `{"reason": "  "}`},
+        {"zero duration", uuid.NewString(), `{"reason": "spam", "duration_seconds": 0}`},
+        {"malformed", uuid.NewString(), `{`},
+    }
+    handlers := map[string]http.HandlerFunc{
+        "suspend":    cfg.suspendUserHandler,
+        "shadow-ban": cfg.shadowBanUserHandler,
+    }
+
+    for name, handler := range handlers {
+        for _, tt := range tests {
+            t.Run(name+"/"+tt.name, func(t *testing.T) {
+                req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+tt.target+"/"+name, strings.NewReader(tt.body))
+                req.SetPathValue("userID", tt.target)
+                req = req.WithContext(context.WithValue(req.Context(), userIDContextKey, moderatorID))
+                rec := httptest.NewRecorder()
+                handler(rec, req)
+                assert.Equal(t, http.StatusBadRequest, rec.Code)
+            })
+        }
+    }
+}
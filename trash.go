@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/KrishKoria/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *APIConfig) getTrashHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    chirps, err := cfg.Store.GetDeletedChirpsByAuthor(r.Context(), database.GetDeletedChirpsByAuthorParams{
+        UserID:       userID,
+        DeletedSince: time.Now().UTC().Add(-cfg.ChirpRetention),
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve deleted chirps", err)
+        return
+    }
+
+    response := []ChirpResponse{}
+    for _, chirp := range chirps {
+        response = append(response, mapChirp(chirp))
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) restoreChirpHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid chirp ID")
+        return
+    }
+
+    chirp, err := cfg.Store.GetDeletedChirpByID(r.Context(), chirpID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Chirp not found in trash")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve chirp", err)
+        return
+    }
+
+    if chirp.UserID != userID {
+        respondWithError(w, http.StatusForbidden, "You can only restore your own chirps")
+        return
+    }
+
+    now := time.Now().UTC()
+    if now.Sub(chirp.DeletedAt.Time) > cfg.ChirpRetention {
+        respondWithError(w, http.StatusGone, "Chirp is past the restore window")
+        return
+    }
+
+    restored, err := cfg.Store.RestoreChirp(r.Context(), database.RestoreChirpParams{
+        Now: now,
+        ID:  chirpID,
+    })
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Chirp not found in trash")
+            return
+        }
+        respondWithInternalError(w, "Failed to restore chirp", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, mapChirp(restored))
+}
+
+// purgeExpiredChirps permanently removes chirps that have been in the trash
+// longer than the retention window. It runs until ctx is cancelled.
+func (cfg *APIConfig) purgeExpiredChirps(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if _, err := cfg.Store.PurgeDeletedChirps(ctx, time.Now().UTC().Add(-cfg.ChirpRetention)); err != nil {
+            cfg.Logger.Error("purge deleted chirps", "error", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
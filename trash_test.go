@@ -0,0 +1,70 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/store"
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRestoreChirpRetention(t *testing.T) {
+    ctx := context.Background()
+    cfg := &APIConfig{
+        Store:          store.NewMemory(),
+        JWTSecret:      "secret",
+        ChirpRetention: 30 * 24 * time.Hour,
+    }
+    alice, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: "alice@example.com", HashedPassword: "hash"})
+    require.NoError(t, err)
+    bob, err := cfg.Store.CreateUser(ctx, database.CreateUserParams{Email: "bob@example.com", HashedPassword: "hash"})
+    require.NoError(t, err)
+
+    trash := func(deletedAgo time.Duration) uuid.UUID {
+        now := time.Now()
+        chirp, err := cfg.Store.CreateChirp(ctx, database.CreateChirpParams{
+            ID:         uuid.New(),
+            CreatedAt:  now,
+            UpdatedAt:  now,
+            Body:       "oops",
+            UserID:     alice.ID,
+            Visibility: visibilityPublic,
+        })
+        require.NoError(t, err)
+        require.NoError(t, cfg.Store.SoftDeleteChirp(ctx, database.SoftDeleteChirpParams{
+            Now: time.Now().UTC().Add(-deletedAgo),
+            ID:  chirp.ID,
+        }))
+        return chirp.ID
+    }
+    restore := func(userID, chirpID uuid.UUID) int {
+        token, err := auth.MakeJWT(userID, cfg.JWTSecret, time.Hour)
+        require.NoError(t, err)
+        req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID.String()+"/restore", nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        req.SetPathValue("chirpID", chirpID.String())
+        rec := httptest.NewRecorder()
+        cfg.restoreChirpHandler(rec, req)
+        return rec.Code
+    }
+
+    recent := trash(time.Hour)
+    expired := trash(31 * 24 * time.Hour)
+
+    assert.Equal(t, http.StatusForbidden, restore(bob.ID, recent))
+    assert.Equal(t, http.StatusGone, restore(alice.ID, expired))
+    assert.Equal(t, http.StatusOK, restore(alice.ID, recent))
+    assert.Equal(t, http.StatusNotFound, restore(alice.ID, recent))
+
+    _, err = cfg.Store.GetChirpByID(ctx, database.GetChirpByIDParams{ID: recent, ViewerID: bob.ID, Now: time.Now()})
+    assert.NoError(t, err)
+    _, err = cfg.Store.GetDeletedChirpByID(ctx, expired)
+    assert.NoError(t, err)
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
     "github.com/KrishKoria/Chirpy/internal/auth"
     "github.com/KrishKoria/Chirpy/internal/database"
-	"github.com/lib/pq"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/KrishKoria/Chirpy/internal/store"
     "time"
     "github.com/google/uuid"
 )
@@ -38,22 +40,22 @@ func (cfg *APIConfig) UsersHandler(w http.ResponseWriter, r *http.Request) {
 
     hashedPassword, err := auth.HashPassword(req.Password)
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to process password")
+        respondWithInternalError(w, "Failed to process password", err)
         return
     }
 
 
-    user, err := cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
+    user, err := cfg.Store.CreateUser(r.Context(), database.CreateUserParams{
         Email:    req.Email,
         HashedPassword: hashedPassword,
     })
     if err != nil {
-        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+        if errors.Is(err, store.ErrEmailTaken) {
             respondWithError(w, http.StatusConflict, "Email already exists")
             return
         }
 
-        respondWithError(w, http.StatusInternalServerError, "Failed to create user")
+        respondWithInternalError(w, "Failed to create user", err)
         return
     }
 
@@ -63,7 +65,7 @@ func (cfg *APIConfig) UsersHandler(w http.ResponseWriter, r *http.Request) {
         UpdatedAt: user.UpdatedAt,
         Email:     user.Email,
         IsChirpyRed: user.IsChirpyRed,
-
+        IsPrivate: user.IsPrivate,
     }
 
     respondWithJSON(w, http.StatusCreated, mappedUser)
@@ -98,34 +100,42 @@ func (cfg *APIConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    user, err := cfg.DB.GetUserByEmail(r.Context(), req.Email)
+    user, err := cfg.Store.GetUserByEmail(r.Context(), req.Email)
     if err != nil {
+        cfg.Metrics.Logins.WithLabelValues(metrics.LoginInvalidCredentials).Inc()
         respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
         return
     }
 
     err = auth.CheckPasswordHash(req.Password, user.HashedPassword)
     if err != nil {
+        cfg.Metrics.Logins.WithLabelValues(metrics.LoginInvalidCredentials).Inc()
         respondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
         return
     }
 
-    token, err := auth.MakeJWT(user.ID, cfg.JWTSecret, time.Hour)
+    if userSuspended(user) {
+        cfg.Metrics.Logins.WithLabelValues(metrics.LoginSuspended).Inc()
+        respondWithError(w, http.StatusForbidden, "Account suspended")
+        return
+    }
+
+    token, err := auth.MakeJWT(user.ID, cfg.JWTSecret, cfg.AccessTokenTTL)
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
+        respondWithInternalError(w, "Failed to generate authentication token", err)
         return
     }
 
     refreshToken, err := auth.MakeRefreshToken()
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to generate refresh token")
+        respondWithInternalError(w, "Failed to generate refresh token", err)
         return
     }
 
     now := time.Now().UTC()
-    expiresAt := now.AddDate(0, 0, 60)
+    expiresAt := now.Add(cfg.RefreshTokenTTL)
     
-    err = cfg.DB.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+    err = cfg.Store.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
         Token:      refreshToken,
         UserID:     user.ID,
         CreatedAt:  now,
@@ -134,7 +144,7 @@ func (cfg *APIConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
     })
 
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to store refresh token")
+        respondWithInternalError(w, "Failed to store refresh token", err)
         return
     }
     response := loginResponse{
@@ -147,6 +157,7 @@ func (cfg *APIConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
         IsChirpyRed: user.IsChirpyRed,
     }
 
+    cfg.Metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
     respondWithJSON(w, http.StatusOK, response)
 }
 
@@ -160,9 +171,9 @@ func (cfg *APIConfig) ResetHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    err := cfg.DB.DeleteAllUsers(r.Context())
+    err := cfg.Store.DeleteAllUsers(r.Context())
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to delete users")
+        respondWithInternalError(w, "Failed to delete users", err)
         return
     }
 
@@ -173,15 +184,8 @@ func (cfg *APIConfig) ResetHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (cfg *APIConfig) updateUserHandler(w http.ResponseWriter, r *http.Request) {
-    tokenString, err := auth.GetBearerToken(r.Header)
-    if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Authentication required")
-        return
-    }
-    
-    userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
-    if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
         return
     }
     
@@ -208,11 +212,11 @@ func (cfg *APIConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
     
     hashedPassword, err := auth.HashPassword(req.Password)
     if err != nil {
-        respondWithError(w, http.StatusInternalServerError, "Failed to process password")
+        respondWithInternalError(w, "Failed to process password", err)
         return
     }
     
-    updatedUser, err := cfg.DB.UpdateUser(r.Context(), database.UpdateUserParams{
+    updatedUser, err := cfg.Store.UpdateUser(r.Context(), database.UpdateUserParams{
         ID:             userID,
         Email:          req.Email,
         HashedPassword: hashedPassword,
@@ -220,11 +224,11 @@ func (cfg *APIConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
     })
     
     if err != nil {
-        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+        if errors.Is(err, store.ErrEmailTaken) {
             respondWithError(w, http.StatusConflict, "Email already exists")
             return
         }
-        respondWithError(w, http.StatusInternalServerError, "Failed to update user")
+        respondWithInternalError(w, "Failed to update user", err)
         return
     }
     
@@ -245,4 +249,54 @@ func (cfg *APIConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
     }
     
     respondWithJSON(w, http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+func (cfg *APIConfig) updatePrivacyHandler(w http.ResponseWriter, r *http.Request) {
+    userID, ok := cfg.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    type privacyRequest struct {
+        IsPrivate *bool `json:"is_private"`
+    }
+
+    var req privacyRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
+    }
+
+    if req.IsPrivate == nil {
+        respondWithError(w, http.StatusBadRequest, "is_private is required")
+        return
+    }
+
+    user, err := cfg.DB.SetUserPrivacy(r.Context(), database.SetUserPrivacyParams{
+        ID:        userID,
+        IsPrivate: *req.IsPrivate,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to update privacy", err)
+        return
+    }
+
+    // Going public lets everyone follow freely, so anyone still waiting is
+    // let in rather than left with a request nobody will look at.
+    if !user.IsPrivate {
+        err = cfg.DB.ApproveAllFollowRequests(r.Context(), userID)
+        if err != nil {
+            respondWithInternalError(w, "Failed to approve pending follow requests", err)
+            return
+        }
+    }
+
+    respondWithJSON(w, http.StatusOK, User{
+        ID:          user.ID,
+        CreatedAt:   user.CreatedAt,
+        UpdatedAt:   user.UpdatedAt,
+        Email:       user.Email,
+        IsChirpyRed: user.IsChirpyRed,
+        IsPrivate:   user.IsPrivate,
+    })
+}
@@ -1,56 +1,312 @@
 package main
 
 import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
     "encoding/json"
+    "errors"
+    "fmt"
+    "io"
     "net/http"
+    "time"
 
     "github.com/google/uuid"
     "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
 )
 
+const (
+    webhookProviderPolka = "polka"
+
+    webhookStatusReceived  = "received"
+    webhookStatusProcessed = "processed"
+    webhookStatusFailed    = "failed"
+)
+
+var (
+    errInvalidWebhookPayload = errors.New("invalid webhook payload")
+    errWebhookUserNotFound   = errors.New("user not found")
+)
+
+type polkaEvent struct {
+    ID    string `json:"id"`
+    Event string `json:"event"`
+    Data  struct {
+        UserID           string    `json:"user_id"`
+        Plan             string    `json:"plan"`
+        CurrentPeriodEnd time.Time `json:"current_period_end"`
+    } `json:"data"`
+}
+
 func (cfg *APIConfig) polkaWebhookHandler(w http.ResponseWriter, r *http.Request) {
-    apiKey, err := auth.GetAPIKey(r.Header)
+    body, err := io.ReadAll(r.Body)
     if err != nil {
-        respondWithError(w, http.StatusUnauthorized, "API key required")
+        respondWithError(w, http.StatusBadRequest, "Failed to read request body")
         return
     }
 
-    if apiKey != cfg.PolkaKey {
-        respondWithError(w, http.StatusUnauthorized, "Invalid API key")
+    if err := cfg.PolkaWebhooks.Verify(r.Header, body); err != nil {
+        if errors.Is(err, auth.ErrReplayedWebhook) {
+            cfg.countInboundWebhook(metrics.WebhookDuplicate)
+            respondWithError(w, http.StatusConflict, err.Error())
+            return
+        }
+        cfg.countInboundWebhook(metrics.WebhookRejected)
+        respondWithError(w, http.StatusUnauthorized, err.Error())
         return
     }
 
-    type webhookData struct {
-        UserID string `json:"user_id"`
+    var payload polkaEvent
+    if err := json.Unmarshal(body, &payload); err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+        return
     }
 
-    type webhookPayload struct {
-        Event string      `json:"event"`
-        Data  webhookData `json:"data"`
+    event, err := cfg.DB.RecordWebhookEvent(r.Context(), database.RecordWebhookEventParams{
+        Provider:  webhookProviderPolka,
+        EventID:   polkaEventID(payload, body),
+        EventType: payload.Event,
+        Payload:   body,
+    })
+    if err == sql.ErrNoRows {
+        cfg.countInboundWebhook(metrics.WebhookDuplicate)
+        cfg.respondToClaimedWebhookEvent(w, r, polkaEventID(payload, body))
+        return
+    }
+    if err != nil {
+        respondWithInternalError(w, "Failed to record webhook event", err)
+        return
     }
 
-    var payload webhookPayload
-    if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-        respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+    if err := cfg.processWebhookEvent(r.Context(), event); err != nil {
+        cfg.countInboundWebhook(metrics.WebhookFailed)
+        respondWithWebhookError(w, err)
         return
     }
 
-    if payload.Event != "user.upgraded" {
-        w.WriteHeader(http.StatusNoContent)
+    cfg.PolkaWebhooks.Delivered(r.Header, body)
+    cfg.countInboundWebhook(metrics.WebhookProcessed)
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// respondToClaimedWebhookEvent answers a delivery of an event that could not
+// be claimed. One already processed is acknowledged. One another request is
+// still processing gets a 409, so the sender retries instead of taking the
+// event as handled; if that request died, the retry claims the event once
+// its lease runs out.
+func (cfg *APIConfig) respondToClaimedWebhookEvent(w http.ResponseWriter, r *http.Request, eventID string) {
+    status, err := cfg.DB.GetWebhookEventStatus(r.Context(), database.GetWebhookEventStatusParams{
+        Provider: webhookProviderPolka,
+        EventID:  eventID,
+    })
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve webhook event", err)
+        return
+    }
+    if status != webhookStatusProcessed {
+        respondWithError(w, http.StatusConflict, "Webhook event is already being processed")
         return
     }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithWebhookError reports why processing a webhook event failed.
+func respondWithWebhookError(w http.ResponseWriter, err error) {
+    switch {
+    case errors.Is(err, errInvalidWebhookPayload):
+        respondWithError(w, http.StatusBadRequest, err.Error())
+    case errors.Is(err, errWebhookUserNotFound):
+        respondWithError(w, http.StatusNotFound, "User not found")
+    case errors.Is(err, errNoSubscription):
+        respondWithError(w, http.StatusNotFound, "Subscription not found")
+    default:
+        respondWithInternalError(w, "Failed to process webhook event", err)
+    }
+}
+
+func (cfg *APIConfig) countInboundWebhook(outcome string) {
+    cfg.Metrics.Webhooks.WithLabelValues(metrics.WebhookInbound, outcome).Inc()
+}
+
+// polkaEventID returns the delivery's event ID. Older payloads carry none, so
+// those are keyed by a hash of the body instead.
+func polkaEventID(payload polkaEvent, body []byte) string {
+    if payload.ID != "" {
+        return payload.ID
+    }
+    sum := sha256.Sum256(body)
+    return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// processWebhookEvent applies a recorded event and stores the outcome on it.
+func (cfg *APIConfig) processWebhookEvent(ctx context.Context, event database.WebhookEvent) error {
+    var err error
+    switch event.Provider {
+    case webhookProviderPolka:
+        err = cfg.applyPolkaEvent(ctx, event.Payload)
+    default:
+        err = fmt.Errorf("unknown webhook provider %q", event.Provider)
+    }
+
+    // Record the outcome even if the request was cancelled while applying it.
+    ctx = context.WithoutCancel(ctx)
+    if err != nil {
+        markErr := cfg.DB.MarkWebhookEventFailed(ctx, database.MarkWebhookEventFailedParams{
+            ID:        event.ID,
+            LastError: sql.NullString{String: err.Error(), Valid: true},
+        })
+        return errors.Join(err, markErr)
+    }
+
+    return cfg.DB.MarkWebhookEventProcessed(ctx, event.ID)
+}
+
+func (cfg *APIConfig) applyPolkaEvent(ctx context.Context, body []byte) error {
+    var payload polkaEvent
+    if err := json.Unmarshal(body, &payload); err != nil {
+        return errInvalidWebhookPayload
+    }
+
+    switch payload.Event {
+    case "user.upgraded", "subscription.renewed", "user.downgraded",
+        "subscription.payment_failed", "subscription.refunded":
+    default:
+        return nil
+    }
 
     userID, err := uuid.Parse(payload.Data.UserID)
     if err != nil {
-        respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+        return fmt.Errorf("%w: invalid user ID format", errInvalidWebhookPayload)
+    }
+
+    switch payload.Event {
+    case "user.downgraded":
+        return cfg.cancelSubscription(ctx, userID)
+    case "subscription.payment_failed":
+        return cfg.markSubscriptionPastDue(ctx, userID)
+    case "subscription.refunded":
+        return cfg.refundSubscription(ctx, userID)
+    default:
+        return cfg.activateSubscription(ctx, userID, payload.Data.Plan, payload.Data.CurrentPeriodEnd)
+    }
+}
+
+type webhookEventResponse struct {
+    ID          uuid.UUID       `json:"id"`
+    Provider    string          `json:"provider"`
+    EventID     string          `json:"event_id"`
+    EventType   string          `json:"event_type"`
+    Payload     json.RawMessage `json:"payload"`
+    Status      string          `json:"status"`
+    Attempts    int32           `json:"attempts"`
+    LastError   string          `json:"last_error,omitempty"`
+    ReceivedAt  time.Time       `json:"received_at"`
+    ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+}
+
+func mapWebhookEvent(event database.WebhookEvent) webhookEventResponse {
+    response := webhookEventResponse{
+        ID:         event.ID,
+        Provider:   event.Provider,
+        EventID:    event.EventID,
+        EventType:  event.EventType,
+        Payload:    event.Payload,
+        Status:     event.Status,
+        Attempts:   event.Attempts,
+        LastError:  event.LastError.String,
+        ReceivedAt: event.ReceivedAt,
+    }
+    if event.ProcessedAt.Valid {
+        response.ProcessedAt = &event.ProcessedAt.Time
+    }
+    return response
+}
+
+func (cfg *APIConfig) listWebhookEventsHandler(w http.ResponseWriter, r *http.Request) {
+    status := r.URL.Query().Get("status")
+    switch status {
+    case "", webhookStatusReceived, webhookStatusProcessed, webhookStatusFailed:
+    default:
+        respondWithError(w, http.StatusBadRequest, "Invalid status")
         return
     }
 
-    _, err = cfg.DB.UpgradeUserToChirpyRed(r.Context(), userID)
+    events, err := cfg.DB.ListWebhookEvents(r.Context(), status)
     if err != nil {
-        respondWithError(w, http.StatusNotFound, "User not found")
+        respondWithInternalError(w, "Failed to retrieve webhook events", err)
         return
     }
 
-    w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+    response := []webhookEventResponse{}
+    for _, event := range events {
+        response = append(response, mapWebhookEvent(event))
+    }
+
+    respondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *APIConfig) getWebhookEventHandler(w http.ResponseWriter, r *http.Request) {
+    eventID, err := uuid.Parse(r.PathValue("eventID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+        return
+    }
+
+    event, err := cfg.DB.GetWebhookEvent(r.Context(), eventID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Webhook event not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to retrieve webhook event", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, mapWebhookEvent(event))
+}
+
+func (cfg *APIConfig) replayWebhookEventHandler(w http.ResponseWriter, r *http.Request) {
+    eventID, err := uuid.Parse(r.PathValue("eventID"))
+    if err != nil {
+        respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+        return
+    }
+
+    event, err := cfg.DB.RetryWebhookEvent(r.Context(), eventID)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            respondWithError(w, http.StatusNotFound, "Webhook event not found")
+            return
+        }
+        respondWithInternalError(w, "Failed to replay webhook event", err)
+        return
+    }
+
+    // The outcome is also kept on the event, for the list and detail views.
+    if err := cfg.processWebhookEvent(r.Context(), event); err != nil {
+        respondWithWebhookError(w, err)
+        return
+    }
+
+    event, err = cfg.DB.GetWebhookEvent(r.Context(), eventID)
+    if err != nil {
+        respondWithInternalError(w, "Failed to retrieve webhook event", err)
+        return
+    }
+
+    respondWithJSON(w, http.StatusOK, mapWebhookEvent(event))
+}
+
+// newPolkaVerifier builds the Polka signature verifier. Several keys may be
+// given so a new secret can be added before the old one is retired.
+func newPolkaVerifier(keys []string) *auth.WebhookVerifier {
+    return auth.NewWebhookVerifier(
+        "X-Polka-Signature",
+        "X-Polka-Timestamp",
+        5*time.Minute,
+        keys...,
+    )
+}
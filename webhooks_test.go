@@ -0,0 +1,127 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/KrishKoria/Chirpy/internal/auth"
+    "github.com/KrishKoria/Chirpy/internal/database"
+    "github.com/KrishKoria/Chirpy/internal/metrics"
+    "github.com/google/uuid"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+)
+
+func polkaRequest(key string, body []byte) *http.Request {
+    now := time.Now()
+    req := httptest.NewRequest(http.MethodPost, "/api/polka/webhooks", bytes.NewReader(body))
+    req.Header.Set("X-Polka-Signature", auth.SignWebhook(key, now, body))
+    req.Header.Set("X-Polka-Timestamp", strconv.FormatInt(now.Unix(), 10))
+    return req
+}
+
+// Deliveries turned away by the verifier never reach the database.
+func TestPolkaWebhookRejects(t *testing.T) {
+    cfg := &APIConfig{PolkaWebhooks: newPolkaVerifier([]string{"key"}), Metrics: metrics.New()}
+    body := []byte(`{"id": "evt_1", "event": "user.upgraded", "data": {"user_id": "` + uuid.NewString() + `"}}`)
+
+    rec := httptest.NewRecorder()
+    cfg.polkaWebhookHandler(rec, polkaRequest("wrong-key", body))
+    assert.Equal(t, http.StatusUnauthorized, rec.Code)
+    assert.Equal(t, 1.0, testutil.ToFloat64(cfg.Metrics.Webhooks.WithLabelValues(metrics.WebhookInbound, metrics.WebhookRejected)))
+
+    req := polkaRequest("key", body)
+    cfg.PolkaWebhooks.Delivered(req.Header, body)
+    rec = httptest.NewRecorder()
+    cfg.polkaWebhookHandler(rec, req)
+    assert.Equal(t, http.StatusConflict, rec.Code)
+    assert.Equal(t, 1.0, testutil.ToFloat64(cfg.Metrics.Webhooks.WithLabelValues(metrics.WebhookInbound, metrics.WebhookDuplicate)))
+}
+
+func TestPolkaEventID(t *testing.T) {
+    body := []byte(`{"event": "user.upgraded"}`)
+    assert.Equal(t, "evt_1", polkaEventID(polkaEvent{ID: "evt_1"}, body))
+
+    // Without an ID, a redelivery of the same body gets the same key.
+    hashed := polkaEventID(polkaEvent{}, body)
+    assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, hashed)
+    assert.Equal(t, hashed, polkaEventID(polkaEvent{}, body))
+    assert.NotEqual(t, hashed, polkaEventID(polkaEvent{}, []byte(`{"event": "user.downgraded"}`)))
+}
+
+// Events that are ignored or malformed are settled before anything is
+// written.
+func TestApplyPolkaEvent(t *testing.T) {
+    cfg := &APIConfig{}
+
+    assert.NoError(t, cfg.applyPolkaEvent(context.Background(), []byte(`{"event": "user.created"}`)))
+    assert.ErrorIs(t, cfg.applyPolkaEvent(context.Background(), []byte(`not json`)), errInvalidWebhookPayload)
+    assert.ErrorIs(t, cfg.applyPolkaEvent(context.Background(), []byte(`{"event": "user.upgraded", "data": {"user_id": "nope"}}`)), errInvalidWebhookPayload)
+}
+
+func TestRespondWithWebhookError(t *testing.T) {
+    tests := []struct {
+        err  error
+        want int
+    }{
+        {errInvalidWebhookPayload, http.StatusBadRequest},
+        {errWebhookUserNotFound, http.StatusNotFound},
+        {errNoSubscription, http.StatusNotFound},
+        {errors.Join(errNoSubscription, errors.New("mark failed")), http.StatusNotFound},
+        {errors.New("connection refused"), http.StatusInternalServerError},
+    }
+
+    for _, tt := range tests {
+        rec := httptest.NewRecorder()
+        respondWithWebhookError(rec, tt.err)
+        assert.Equal(t, tt.want, rec.Code, tt.err.Error())
+    }
+}
+
+func TestWebhookEventAdminValidation(t *testing.T) {
+    cfg := &APIConfig{}
+
+    rec := httptest.NewRecorder()
+    cfg.listWebhookEventsHandler(rec, httptest.NewRequest(http.MethodGet, "/admin/webhooks?status=pending", nil))
+    assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+    for _, handler := range []http.HandlerFunc{cfg.getWebhookEventHandler, cfg.replayWebhookEventHandler} {
+        req := httptest.NewRequest(http.MethodGet, "/admin/webhooks/nope", nil)
+        req.SetPathValue("eventID", "nope")
+        rec := httptest.NewRecorder()
+        handler(rec, req)
+        assert.Equal(t, http.StatusBadRequest, rec.Code)
+    }
+}
+
+func TestMapWebhookEvent(t *testing.T) {
+    received := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+    event := database.WebhookEvent{
+        ID:         uuid.New(),
+        Provider:   webhookProviderPolka,
+        EventID:    "evt_1",
+        EventType:  "user.upgraded",
+        Payload:    []byte(`{}`),
+        Status:     webhookStatusFailed,
+        Attempts:   2,
+        LastError:  sql.NullString{String: "user not found", Valid: true},
+        ReceivedAt: received,
+    }
+
+    response := mapWebhookEvent(event)
+    assert.Equal(t, "user not found", response.LastError)
+    assert.Nil(t, response.ProcessedAt)
+
+    event.ProcessedAt = sql.NullTime{Time: received.Add(time.Minute), Valid: true}
+    response = mapWebhookEvent(event)
+    if assert.NotNil(t, response.ProcessedAt) {
+        assert.Equal(t, received.Add(time.Minute), *response.ProcessedAt)
+    }
+}